@@ -0,0 +1,129 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Aggregate runs each named part concurrently and combines the results into one
+// response, for dashboards that should still return whatever succeeded.
+//
+// Successful results go into Data keyed by part name. Failed parts are reported in
+// Errors (one FieldError per part, code "part_failed"), sorted by name. Their message
+// is generic so internal details don't leak to clients; the part errors, including
+// recovered panics, are joined into the response's Cause for logging. The response
+// is a 200 unless a part named in critical failed, in which case it is a 500 that
+// still carries the successful results.
+func Aggregate(parts map[string]func() (any, error), critical ...string) *APIResponse {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		data     = make(map[string]any, len(parts))
+		failures []FieldError
+		causes   []error
+	)
+
+	for name, part := range parts {
+		wg.Add(1)
+		go func(name string, part func() (any, error)) {
+			defer wg.Done()
+			v, err := runPart(part)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures = append(failures, NewFieldError(name, "part_failed", "Part could not be loaded"))
+				causes = append(causes, fmt.Errorf("part %q: %w", name, err))
+				return
+			}
+			data[name] = v
+		}(name, part)
+	}
+	wg.Wait()
+
+	slices.SortFunc(failures, func(a, b FieldError) int { return strings.Compare(a.Field, b.Field) })
+
+	var rsp *APIResponse
+	switch {
+	case slices.ContainsFunc(failures, func(f FieldError) bool { return slices.Contains(critical, f.Field) }):
+		rsp = InternalServerError("A required part of the request failed", "PART_FAILED")
+		rsp.Data = data
+	case len(failures) > 0:
+		rsp = OK("Request partially succeeded", data)
+	default:
+		rsp = OK("", data)
+	}
+
+	rsp.Errors = failures
+	if len(causes) > 0 {
+		slices.SortFunc(causes, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+		rsp.WithCause(errors.Join(causes...))
+	}
+	return rsp
+}
+
+// runPart calls part, turning a panic into an error so one broken part fails alone
+// instead of crashing the process.
+func runPart(part func() (any, error)) (v any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return part()
+}
+
+// SafeBuilder assembles a success response from multiple goroutines, e.g. fan-out
+// workers each contributing part of Data. The zero value is ready to use and all
+// methods are safe for concurrent use.
+type SafeBuilder struct {
+	mu       sync.Mutex
+	data     map[string]any
+	meta     map[string]any
+	warnings []string
+}
+
+// AddData sets key in the response Data.
+func (b *SafeBuilder) AddData(key string, value any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.data == nil {
+		b.data = make(map[string]any)
+	}
+	b.data[key] = value
+}
+
+// AddMeta sets key in the response Meta.
+func (b *SafeBuilder) AddMeta(key string, value any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.meta == nil {
+		b.meta = make(map[string]any)
+	}
+	b.meta[key] = value
+}
+
+// AddWarning appends a non-fatal warning to the response.
+func (b *SafeBuilder) AddWarning(msg string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.warnings = append(b.warnings, msg)
+}
+
+// Build returns an OK response holding a snapshot of what has been added so far.
+// Later additions don't affect responses already built.
+func (b *SafeBuilder) Build() *APIResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rsp := OK("", maps.Clone(b.data))
+	if b.meta != nil {
+		rsp.Meta = maps.Clone(b.meta)
+	}
+	rsp.Warnings = slices.Clone(b.warnings)
+	return rsp
+}
@@ -0,0 +1,87 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	parts := map[string]func() (any, error){
+		"orders":  func() (any, error) { return 12, nil },
+		"revenue": func() (any, error) { return nil, errors.New("billing unavailable") },
+		"users":   func() (any, error) { return 3, nil },
+	}
+
+	t.Run("non critical failure", func(t *testing.T) {
+		got := Aggregate(parts)
+
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+		assert.True(t, got.Success)
+		assert.Equal(t, map[string]any{"orders": 12, "users": 3}, got.Data)
+		assert.Equal(t, []FieldError{NewFieldError("revenue", "part_failed", "Part could not be loaded")}, got.Errors)
+		assert.EqualError(t, got.Cause(), `part "revenue": billing unavailable`)
+	})
+
+	t.Run("panicking part", func(t *testing.T) {
+		got := Aggregate(map[string]func() (any, error){
+			"orders": func() (any, error) { return 1, nil },
+			"users":  func() (any, error) { panic("nil map") },
+		})
+
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+		assert.Equal(t, map[string]any{"orders": 1}, got.Data)
+		assert.Equal(t, []FieldError{NewFieldError("users", "part_failed", "Part could not be loaded")}, got.Errors)
+		assert.EqualError(t, got.Cause(), `part "users": panic: nil map`)
+	})
+
+	t.Run("critical failure", func(t *testing.T) {
+		got := Aggregate(parts, "revenue")
+
+		assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+		assert.False(t, got.Success)
+		assert.Equal(t, map[string]any{"orders": 12, "users": 3}, got.Data)
+	})
+
+	t.Run("all parts succeed", func(t *testing.T) {
+		got := Aggregate(map[string]func() (any, error){
+			"orders": func() (any, error) { return 1, nil },
+		}, "orders")
+
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+		assert.Equal(t, "Request was successful", got.Message)
+		assert.Empty(t, got.Errors)
+	})
+}
+
+func TestSafeBuilder(t *testing.T) {
+	var b SafeBuilder
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := strconv.Itoa(i)
+			b.AddData(key, i)
+			b.AddMeta(key, true)
+			if i%10 == 0 {
+				b.AddWarning("slow worker " + key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got := b.Build()
+	assert.Equal(t, http.StatusOK, got.StatusCode)
+	assert.Len(t, got.Data, 20)
+	assert.Len(t, got.Meta, 20)
+	assert.ElementsMatch(t, []string{"slow worker 0", "slow worker 10"}, got.Warnings)
+
+	b.AddData("late", 1)
+	assert.Len(t, got.Data, 20)
+}
@@ -4,27 +4,96 @@ import (
 	"bytes"
 	"encoding/gob"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"net/http"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
+// EnvelopeVersion is the schema version stamped on every response built by
+// NewAPIResponse. Bump it when the shape of the envelope changes.
+var EnvelopeVersion = "1"
+
+// MaxMessageLength bounds the size of Message in bytes. Longer messages are
+// truncated with an ellipsis by NewAPIResponse. 0 means unlimited.
+var MaxMessageLength int
+
+// DebugMode enables development-only additions to responses, such as timing
+// information. Keep it off in production.
+var DebugMode bool
+
+// StrictStatusCodes makes the constructors panic when given a status code outside
+// their range (e.g. a 2xx passed to Error). This fails fast in development and tests.
+// When false, the status code is clamped to the nearest valid one (500 for Error,
+// 200 for Success and lists) and a warning is logged instead.
+var StrictStatusCodes = true
+
+// IsSuccessStatus decides the Success flag wherever it is derived from a status code:
+// in Success and the helpers built on it (OK, Created, List, ForStatus, ...) and in
+// FromHTTPResponse. By default only 2xx codes are successful, so e.g. a 3xx built
+// with Success is sent with `"success": false`. The constructor guards are separate:
+// Success still accepts any non-error code, and Error any 4xx/5xx code.
+var IsSuccessStatus = func(statusCode int) bool {
+	return statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices
+}
+
+// RequireErrorCode makes Error, and every error constructor built on it, panic when
+// called with an empty errorCode. It enforces that clients can always branch on a
+// machine-readable code, catching handlers that forgot to set one during tests.
+var RequireErrorCode bool
+
 // APIResponse defines the standard structure for all API responses.
 //
 // StatusCode: HTTP status code associated with the response. Not included in JSON output.
+// Version: Envelope schema version, set from EnvelopeVersion so clients can branch on it.
+// RequestID: (Optional) Correlation ID of the request that produced the response.
 // Success: Indicates whether the request was successful (true) or not (false).
 // Message: Human-readable message describing the response outcome.
 // ErrorCode: Optional Application-specific error code for internal reference.
+// HelpURL: (Optional) Documentation for ErrorCode, set from RegisterErrorDoc.
 // Data: Holds the actual response data. Its type is any to allow flexibility for different data formats.
+// DataType: (Optional) Discriminator naming the shape of Data, for polymorphic payloads.
 // Meta: (Optional) Holds additional information like pagination details or other metadata.
+// Links: (Optional) Related resource links keyed by relation, e.g. "next" or "self".
+// Warnings: (Optional) Non-fatal issues to report to the client, e.g. use of a deprecated parameter.
+// Details: (Optional) Structured, machine-readable information about the outcome.
+// Errors: (Optional) Field-level validation errors, see ValidationFailed.
+// DryRun: (Optional) Marks a simulated mutation whose changes were not persisted.
+// Headers: (Optional) HTTP headers emitted by Write alongside the response. Not included in JSON output.
 //
 // Note: This struct satisfies Go's error interface, allowing it to be directly returned from functions.
 type APIResponse struct {
-	StatusCode int     `json:"-"`
-	Success    bool    `json:"success"`
-	Message    string  `json:"message"`
-	ErrorCode  *string `json:"errorCode,omitempty"`
-	Data       any     `json:"data,omitempty"`
-	Meta       any     `json:"meta,omitempty"` // for paginations and likes
+	StatusCode int               `json:"-"`
+	Version    string            `json:"version,omitempty"`
+	RequestID  string            `json:"requestId,omitempty"`
+	Success    bool              `json:"success"`
+	Message    string            `json:"message"`
+	ErrorCode  *string           `json:"errorCode,omitempty"`
+	HelpURL    string            `json:"helpUrl,omitempty"`
+	Data       any               `json:"data,omitempty"`
+	DataType   string            `json:"dataType,omitempty"`
+	Meta       any               `json:"meta,omitempty"` // for paginations and likes
+	Links      map[string]string `json:"links,omitempty"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Details    map[string]any    `json:"details,omitempty"`
+	Errors     []FieldError      `json:"errors,omitempty"`
+	DryRun     bool              `json:"dryRun,omitempty"`
+	Headers    http.Header       `json:"-"`
+
+	requireData bool
+	cause       error       // internal cause, never serialized
+	filePath    string      // file streamed as the body, see FileFromPath
+	messageKey  string      // untranslated Message, see WithLocale
+	logAttrs    []slog.Attr // access log fields, see WithLogField
+	noCompress  bool        // see NoCompress
+	errorTree   *ErrorNode  // encoded as Errors, see GroupedErrors
 }
 
 // Error satisfies the `error` interface by returning the response message. This enables
@@ -33,6 +102,136 @@ func (a *APIResponse) Error() string {
 	return a.Message
 }
 
+// SetHeader sets an HTTP header that Write emits along with the response.
+// It replaces any existing values associated with key.
+func (r *APIResponse) SetHeader(key, value string) *APIResponse {
+	if r.Headers == nil {
+		r.Headers = make(http.Header)
+	}
+	r.Headers.Set(key, value)
+	return r
+}
+
+// AddWarning appends a non-fatal warning to the response. It does not affect
+// Success or StatusCode, so a successful request stays successful.
+func (r *APIResponse) AddWarning(msg string) *APIResponse {
+	r.Warnings = append(r.Warnings, msg)
+	return r
+}
+
+// WithDetail adds a key to the response Details.
+func (r *APIResponse) WithDetail(key string, value any) *APIResponse {
+	if r.Details == nil {
+		r.Details = make(map[string]any)
+	}
+	r.Details[key] = value
+	return r
+}
+
+// RequireData makes the response always include the `data` key, encoding a nil
+// Data as `"data": null` instead of omitting it. This gives strongly-typed clients
+// a predictable envelope shape; by default nil Data is omitted.
+func (r *APIResponse) RequireData() *APIResponse {
+	r.requireData = true
+	return r
+}
+
+// WithRequestID sets the correlation ID in the body and makes Write emit it as the
+// `X-Request-ID` header.
+func (r *APIResponse) WithRequestID(id string) *APIResponse {
+	r.RequestID = id
+	return r.SetHeader("X-Request-ID", id)
+}
+
+// WithDataType sets the discriminator clients read before decoding Data into
+// a concrete type.
+func (r *APIResponse) WithDataType(t string) *APIResponse {
+	r.DataType = t
+	return r
+}
+
+// AsDryRun marks the response as the outcome of a dry run (e.g. `?dryRun=true`),
+// telling clients and audit logs that nothing was persisted.
+func (r *APIResponse) AsDryRun() *APIResponse {
+	r.DryRun = true
+	return r
+}
+
+// WithLogField annotates the response with a field for the access log, e.g. the
+// user ID or tenant, so deep handler code needn't thread a logger through. Fields
+// are never serialized to the client; an OnResponse hook emits them with LogAttrs.
+func (r *APIResponse) WithLogField(key string, value any) *APIResponse {
+	r.logAttrs = append(r.logAttrs, slog.Any(key, value))
+	return r
+}
+
+// LogAttrs returns the fields added with WithLogField, in order.
+//
+//	response.OnResponse = func(r *response.APIResponse) {
+//		slog.LogAttrs(context.Background(), slog.LevelInfo, "response", r.LogAttrs()...)
+//	}
+func (r *APIResponse) LogAttrs() []slog.Attr {
+	return slices.Clone(r.logAttrs)
+}
+
+// clone returns a copy of the response that can be modified without affecting r.
+func (r *APIResponse) clone() *APIResponse {
+	c := *r
+	c.Headers = r.Headers.Clone()
+	c.Warnings = slices.Clone(r.Warnings)
+	c.Links = maps.Clone(r.Links)
+	c.Details = maps.Clone(r.Details)
+	c.Errors = slices.Clone(r.Errors)
+	c.logAttrs = slices.Clone(r.logAttrs)
+	return &c
+}
+
+// String implements fmt.Stringer with a concise summary such as
+// `[404] error msg="user not found" code=USR_404`. Data is summarized by type
+// (and length for slices, arrays and maps) rather than dumped.
+//
+// Because APIResponse is an error, fmt prints it with Error for %v and %s; use %+v
+// (see Format) or call String explicitly to get the summary.
+func (r *APIResponse) String() string {
+	var sb strings.Builder
+
+	outcome := "error"
+	if r.Success {
+		outcome = "success"
+	}
+	fmt.Fprintf(&sb, "[%d] %s msg=%q", r.StatusCode, outcome, r.Message)
+
+	if r.ErrorCode != nil {
+		fmt.Fprintf(&sb, " code=%s", *r.ErrorCode)
+	}
+
+	if r.Data != nil {
+		v := reflect.ValueOf(r.Data)
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			fmt.Fprintf(&sb, " data=%T(len=%d)", r.Data, v.Len())
+		default:
+			fmt.Fprintf(&sb, " data=%T", r.Data)
+		}
+	}
+
+	return sb.String()
+}
+
+// Format implements fmt.Formatter so %+v prints the String summary, e.g. in logs and
+// test failures. %v, %s and %q print the message like Error, so wrapping the response
+// with fmt.Errorf keeps working, and %#v prints the struct fields.
+func (r *APIResponse) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		_, _ = io.WriteString(f, r.String())
+	case verb == 'v' && f.Flag('#'):
+		fmt.Fprintf(f, "%#v", (*envelope)(r))
+	default:
+		fmt.Fprintf(f, fmt.FormatString(f, verb), r.Error())
+	}
+}
+
 // ToByte() encodes the response struct as a byte slice using the `gob` package.
 // This can be useful for sending binary data over network connections
 func (r *APIResponse) ToByte() ([]byte, error) {
@@ -54,6 +253,54 @@ func (r *APIResponse) ToJson() (string, error) {
 	return string(byte), err
 }
 
+// jsonBuffer pairs a buffer with an encoder writing to it, so both can be reused.
+type jsonBuffer struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var jsonBufferPool = sync.Pool{
+	New: func() any {
+		jb := &jsonBuffer{}
+		jb.enc = json.NewEncoder(&jb.buf)
+		return jb
+	},
+}
+
+// maxPooledBufferSize keeps unusually large buffers out of the pool.
+const maxPooledBufferSize = 64 << 10
+
+// AppendJSON appends the JSON encoding of the response to dst and returns the
+// extended slice. Encoding goes through a pooled encoder and buffer, so when dst
+// has enough capacity it avoids most of the allocations of ToJson.
+//
+// It is not allocation-free: encoding/json copies the value held in Data (and other
+// interface fields) on every call. BenchmarkAPIResponse_AppendJSON, with a small
+// struct as Data, measures 2 allocs (48 B) per call against 5 (288 B) for ToJson;
+// only a response without Data encodes with no allocation.
+func (r *APIResponse) AppendJSON(dst []byte) ([]byte, error) {
+	jb := jsonBufferPool.Get().(*jsonBuffer)
+	defer func() {
+		if jb.buf.Cap() <= maxPooledBufferSize {
+			jb.buf.Reset()
+			jsonBufferPool.Put(jb)
+		}
+	}()
+
+	// Skip MarshalJSON when there is nothing to customize, avoiding its extra copy.
+	var v any = r
+	if r.plainJSON() {
+		v = (*envelope)(r)
+	}
+
+	if err := jb.enc.Encode(v); err != nil {
+		return dst, err
+	}
+
+	// Encode terminates each value with a newline, which Marshal does not.
+	return append(dst, bytes.TrimSuffix(jb.buf.Bytes(), []byte("\n"))...), nil
+}
+
 // NewAPIResponse constructs a new APIResponse object, encapsulating
 // information about the API response.
 //
@@ -65,38 +312,98 @@ func NewAPIResponse(statusCode int, success bool, msg, errorCode string, data an
 		errCode = nil
 	}
 
+	if MaxMessageLength > 0 {
+		msg = truncateMessage(msg, MaxMessageLength)
+	}
+
 	return &APIResponse{
 		StatusCode: statusCode,
+		Version:    EnvelopeVersion,
 		Success:    success,
 		Message:    msg,
 		ErrorCode:  errCode,
+		HelpURL:    errorDoc(errorCode),
 		Data:       data,
 	}
 }
 
+// truncateMessage shortens msg to at most max bytes, ending it with an ellipsis
+// and never cutting a multi-byte rune in half.
+func truncateMessage(msg string, max int) string {
+	if len(msg) <= max {
+		return msg
+	}
+
+	const ellipsis = "..."
+	cut := max - len(ellipsis)
+	if cut < 0 {
+		cut = max
+	}
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+
+	if max < len(ellipsis) {
+		return msg[:cut]
+	}
+	return msg[:cut] + ellipsis
+}
+
+// invalidStatusCode handles a status code rejected by a constructor guard: it
+// panics with msg when StrictStatusCodes is set, otherwise it logs a warning and
+// returns fallback.
+func invalidStatusCode(msg string, statusCode, fallback int) int {
+	if StrictStatusCodes {
+		panic(msg)
+	}
+
+	slog.Warn(msg, "statusCode", statusCode, "clampedTo", fallback)
+	return fallback
+}
+
 // Error generates an APIResponse representing an error.
 //
 // return Error(http.StatusForbidden, "Access denied", "AUTH_001")
 func Error(statusCode int, msg string, errorCode string) *APIResponse {
 	// Check: only http status error codes are allowed.
 	if statusCode < http.StatusBadRequest {
-		panic("response error: cant set an error response with a non-error http status code")
+		statusCode = invalidStatusCode(
+			"response error: cant set an error response with a non-error http status code",
+			statusCode, http.StatusInternalServerError,
+		)
+	}
+	if RequireErrorCode && strings.TrimSpace(errorCode) == "" {
+		panic("response error: cant set an error response without an error code")
 	}
 
 	return NewAPIResponse(statusCode, false, msg, errorCode, nil)
 }
 
+// ErrorWithData generates an error response that still carries data. Error responses
+// normally have no Data; attaching it is intended for partial failures, e.g. a bulk
+// operation returning the items it created before failing midway.
+//
+// return ErrorWithData(http.StatusConflict, "Item 3 already exists", "PARTIAL_FAILURE", created)
+func ErrorWithData(statusCode int, msg, errorCode string, data any) *APIResponse {
+	rsp := Error(statusCode, msg, errorCode)
+	rsp.Data = data
+	return rsp
+}
+
 // Success generates an APIResponse for a successful request.
 func Success(statusCode int, msg string, data any) *APIResponse {
 	// Check: only http status success codes are allowed.
 	if statusCode >= http.StatusBadRequest {
-		panic("response error: cant set a success response with an error http status code")
+		statusCode = invalidStatusCode(
+			"response error: cant set a success response with an error http status code",
+			statusCode, http.StatusOK,
+		)
 	}
 
 	if msg == "" {
 		msg = "Request was successful"
 	}
-	return NewAPIResponse(statusCode, true, msg, "", data)
+	return NewAPIResponse(statusCode, IsSuccessStatus(statusCode), msg, "", data)
 }
 
 // Creates a api response with (HTTP 200) code
@@ -109,16 +416,58 @@ func Created(msg string, data any) *APIResponse {
 	return Success(http.StatusCreated, msg, data)
 }
 
+// CreatedAt creates a response with (HTTP 201) code whose writer emits a
+// `Location` header pointing to the new resource.
+func CreatedAt(location, msg string, data any) *APIResponse {
+	return Created(msg, data).SetHeader("Location", location)
+}
+
 // Creates a success response with a list of data and meta information.
 func List(msg string, data any, meta any) *APIResponse {
-	if msg == "" {
-		msg = "Request was successful"
+	return ListWithStatus(http.StatusOK, msg, data, meta)
+}
+
+// ListWithStatus creates a list response with a custom 2xx status code,
+// e.g. 206 for partial content.
+func ListWithStatus(statusCode int, msg string, data any, meta any) *APIResponse {
+	// Check: only 2xx http status codes are allowed for lists.
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		statusCode = invalidStatusCode(
+			"response error: cant set a list response with a non-2xx http status code",
+			statusCode, http.StatusOK,
+		)
 	}
-	rsp := NewAPIResponse(http.StatusOK, true, msg, "", data)
+
+	rsp := Success(statusCode, msg, data)
 	rsp.Meta = meta
 	return rsp
 }
 
+// ListCounted creates a list response with (HTTP 200) code whose Meta holds the
+// number of items in data as `count`. data must be a slice or array; anything else
+// is a programming error that is logged and yields an InternalServerError with code
+// INVALID_LIST_DATA, so the client never receives a miscounted list.
+func ListCounted(msg string, data any) *APIResponse {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		slog.Error("response error: cant count list data that is not a slice or array",
+			"type", fmt.Sprintf("%T", data))
+		return InternalServerError("", "INVALID_LIST_DATA")
+	}
+
+	return List(msg, data, map[string]any{"count": v.Len()})
+}
+
+// EmptyResult creates a list response with (HTTP 200) code for a valid query that
+// matched nothing. Data is an explicit empty array and Meta carries `totalItems: 0`,
+// so clients never confuse no results with omitted data.
+func EmptyResult(msg string) *APIResponse {
+	if msg == "" {
+		msg = "No results found"
+	}
+	return List(msg, []any{}, map[string]any{"totalItems": 0})
+}
+
 // Creates a response with (HTTP 400) code
 func BadRequest(msg string, errorCode string) *APIResponse {
 	if msg == "" {
@@ -135,6 +484,51 @@ func Unauthorized(msg string, errorCode string) *APIResponse {
 	return Error(http.StatusUnauthorized, msg, errorCode)
 }
 
+// UnauthorizedWithChallenge creates a response with (HTTP 401) code whose writer
+// emits the `WWW-Authenticate` header telling the client how to authenticate, e.g.
+// `Bearer realm="api", error="invalid_token"` for
+// UnauthorizedWithChallenge("Bearer", "api", "invalid_token"). The error parameter
+// is omitted when errorCode is empty.
+func UnauthorizedWithChallenge(scheme, realm, errorCode string) *APIResponse {
+	challenge := scheme + ` realm="` + quoteEscaper.Replace(realm) + `"`
+	if errorCode != "" {
+		challenge += `, error="` + quoteEscaper.Replace(errorCode) + `"`
+	}
+	return Unauthorized("", errorCode).SetHeader("WWW-Authenticate", challenge)
+}
+
+// quoteEscaper escapes a value for use in an HTTP quoted-string.
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// Creates a response with (HTTP 402) code, e.g. when a quota is exceeded.
+// Use WithUpgradeURL to point the client at the upgrade page.
+func PaymentRequired(msg string, errorCode string) *APIResponse {
+	if msg == "" {
+		msg = "Payment is required to perform the requested action"
+	}
+	return Error(http.StatusPaymentRequired, msg, errorCode)
+}
+
+// WithUpgradeURL adds the URL where the client can upgrade their plan to Details
+// under `upgradeUrl`.
+func (r *APIResponse) WithUpgradeURL(url string) *APIResponse {
+	return r.WithDetail("upgradeUrl", url)
+}
+
+// ActionLink is a call to action resolving an error, e.g. "Verify email".
+type ActionLink struct {
+	Label string `json:"label"`
+	Href  string `json:"href"`
+}
+
+// WithActionLink adds a call to action to Details under `actions`, turning a dead-end
+// error (e.g. a 403 until the user accepts the terms) into an actionable one. Clients
+// render the links as buttons, in the order they were added.
+func (r *APIResponse) WithActionLink(label, href string) *APIResponse {
+	actions, _ := r.Details["actions"].([]ActionLink)
+	return r.WithDetail("actions", append(actions, ActionLink{Label: label, Href: href}))
+}
+
 // Creates a response with (HTTP 403) code
 func Forbidden(msg string, errorCode string) *APIResponse {
 	if msg == "" {
@@ -143,6 +537,13 @@ func Forbidden(msg string, errorCode string) *APIResponse {
 	return Error(http.StatusForbidden, msg, errorCode)
 }
 
+// CORSForbidden creates a response with (HTTP 403) code and error code CORS_DENIED
+// for requests from an origin the CORS policy doesn't allow, with `{origin}` in Details.
+func CORSForbidden(origin string) *APIResponse {
+	msg := fmt.Sprintf("Origin '%s' is not allowed by the CORS policy", origin)
+	return Forbidden(msg, "CORS_DENIED").WithDetail("origin", origin)
+}
+
 // Creates a response with (HTTP 404) code
 func NotFound(msg string, errorCode string) *APIResponse {
 	if msg == "" {
@@ -151,6 +552,14 @@ func NotFound(msg string, errorCode string) *APIResponse {
 	return Error(http.StatusNotFound, msg, errorCode)
 }
 
+// Creates a response with (HTTP 406) code
+func NotAcceptable(msg string, errorCode string) *APIResponse {
+	if msg == "" {
+		msg = "Requested response format is not supported"
+	}
+	return Error(http.StatusNotAcceptable, msg, errorCode)
+}
+
 // Creates a response with (HTTP 409) code
 func Conflict(msg string, errorCode string) *APIResponse {
 	if msg == "" {
@@ -159,6 +568,34 @@ func Conflict(msg string, errorCode string) *APIResponse {
 	return Error(http.StatusConflict, msg, errorCode)
 }
 
+// ConflictResource creates a response with (HTTP 409) code and error code ALREADY_EXISTS
+// naming the conflicting resource, with `{resource, id}` in Details so clients can link
+// to the existing record.
+//
+// return ConflictResource("user", "42") // "user with id '42' already exists"
+func ConflictResource(resource, id string) *APIResponse {
+	msg := fmt.Sprintf("%s with id '%s' already exists", resource, id)
+	return Conflict(msg, "ALREADY_EXISTS").
+		WithDetail("resource", resource).
+		WithDetail("id", id)
+}
+
+// Creates a response with (HTTP 412) code
+func PreconditionFailed(msg string, errorCode string) *APIResponse {
+	if msg == "" {
+		msg = "Resource has been modified since it was last fetched"
+	}
+	return Error(http.StatusPreconditionFailed, msg, errorCode)
+}
+
+// Creates a response with (HTTP 451) code
+func UnavailableForLegalReasons(msg string, errorCode string) *APIResponse {
+	if msg == "" {
+		msg = "Requested resource is unavailable for legal reasons"
+	}
+	return Error(http.StatusUnavailableForLegalReasons, msg, errorCode)
+}
+
 // creates a response with (HTTP 500)code
 func InternalServerError(msg string, errorCode string) *APIResponse {
 	if msg == "" {
@@ -167,6 +604,87 @@ func InternalServerError(msg string, errorCode string) *APIResponse {
 	return Error(http.StatusInternalServerError, msg, errorCode)
 }
 
+// errorConstructors maps status codes to the helper building their error response.
+var errorConstructors = map[int]func(msg, errorCode string) *APIResponse{
+	http.StatusBadRequest:                 BadRequest,
+	http.StatusUnauthorized:               Unauthorized,
+	http.StatusPaymentRequired:            PaymentRequired,
+	http.StatusForbidden:                  Forbidden,
+	http.StatusNotFound:                   NotFound,
+	http.StatusNotAcceptable:              NotAcceptable,
+	http.StatusConflict:                   Conflict,
+	http.StatusPreconditionFailed:         PreconditionFailed,
+	http.StatusUnprocessableEntity:        func(msg, errorCode string) *APIResponse { return ValidationFailed(msg, errorCode) },
+	http.StatusUnavailableForLegalReasons: UnavailableForLegalReasons,
+	http.StatusInternalServerError:        InternalServerError,
+}
+
+// ForStatus creates a response for an arbitrary status code, dispatching to the
+// matching helper (NotFound, Conflict, ...) so its default message applies when msg
+// is empty. Other error codes use Error with the status text as default message, and
+// non-error codes use Success. An empty errorCode defaults to the status text in
+// upper snake case, as in FromStatus. Data is kept on error responses too, which is useful
+// when proxying a downstream response.
+func ForStatus(statusCode int, msg, errorCode string, data any) *APIResponse {
+	if statusCode < http.StatusBadRequest {
+		return Success(statusCode, msg, data)
+	}
+
+	if errorCode == "" {
+		errorCode = statusErrorCode(statusCode)
+	}
+
+	var rsp *APIResponse
+	if constructor, ok := errorConstructors[statusCode]; ok {
+		rsp = constructor(msg, errorCode)
+	} else {
+		if msg == "" {
+			msg = http.StatusText(statusCode)
+		}
+		rsp = Error(statusCode, msg, errorCode)
+	}
+	rsp.Data = data
+	return rsp
+}
+
+// FromStatus creates a response for statusCode from its status text alone, e.g. for
+// stubs and generic proxies: http.StatusText is the message, Success follows
+// IsSuccessStatus, and error responses get the status text in upper snake case as
+// error code (404 yields NOT_FOUND).
+func FromStatus(statusCode int) *APIResponse {
+	msg := http.StatusText(statusCode)
+	if msg == "" {
+		msg = fmt.Sprintf("Status %d", statusCode)
+	}
+
+	var errorCode string
+	if statusCode >= http.StatusBadRequest {
+		errorCode = statusErrorCode(statusCode)
+	}
+	return NewAPIResponse(statusCode, IsSuccessStatus(statusCode), msg, errorCode, nil)
+}
+
+// statusErrorCode returns the default error code of statusCode, its status text in
+// upper snake case (404 yields NOT_FOUND, unknown codes such as 599 yield STATUS_599).
+func statusErrorCode(statusCode int) string {
+	text := http.StatusText(statusCode)
+	if text == "" {
+		text = fmt.Sprintf("Status %d", statusCode)
+	}
+	return upperSnake(text)
+}
+
+// upperSnake converts text such as "Request-URI Too Long" to REQUEST_URI_TOO_LONG.
+func upperSnake(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	for i, word := range words {
+		words[i] = strings.ToUpper(strings.ReplaceAll(word, "'", ""))
+	}
+	return strings.Join(words, "_")
+}
+
 // Decodes a byte array into an APIResponse struct.
 func FromJsonToAPIResponse(dataByte []byte) (*APIResponse, error) {
 	var apiResponse APIResponse
@@ -2,10 +2,19 @@ package response
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -13,6 +22,7 @@ import (
 func TestAPIResponse_ToByte(t *testing.T) {
 	wantStruct := &APIResponse{
 		StatusCode: http.StatusOK,
+		Version:    EnvelopeVersion,
 		Success:    true,
 		Message:    "its bytes",
 		ErrorCode:  nil,
@@ -51,6 +61,7 @@ func TestAPIResponse_ToJson(t *testing.T) {
 func TestNewAPIResponse(t *testing.T) {
 	expected := &APIResponse{
 		StatusCode: http.StatusOK,
+		Version:    EnvelopeVersion,
 		Success:    true,
 		Message:    "new",
 		ErrorCode:  nil,
@@ -67,6 +78,7 @@ func TestNewError(t *testing.T) {
 	t.Run("when error type is not empty", func(t *testing.T) {
 		want := &APIResponse{
 			StatusCode: http.StatusBadRequest,
+			Version:    EnvelopeVersion,
 			Success:    false,
 			Message:    "new-error",
 			ErrorCode:  &errorCode,
@@ -79,6 +91,7 @@ func TestNewError(t *testing.T) {
 	t.Run("when error type is empty", func(t *testing.T) {
 		want := &APIResponse{
 			StatusCode: http.StatusBadRequest,
+			Version:    EnvelopeVersion,
 			Success:    false,
 			Message:    "new-error",
 			ErrorCode:  nil,
@@ -95,11 +108,27 @@ func TestNewError(t *testing.T) {
 	})
 }
 
+func TestErrorWithData(t *testing.T) {
+	created := []map[string]int{{"id": 1}, {"id": 2}}
+	got := ErrorWithData(http.StatusConflict, "Item 3 already exists", "PARTIAL_FAILURE", created)
+
+	assert.Equal(t, http.StatusConflict, got.StatusCode)
+	assert.False(t, got.Success)
+	assert.Equal(t, "PARTIAL_FAILURE", *got.ErrorCode)
+
+	v, err := got.ToJson()
+	assert.NoError(t, err)
+	assert.Contains(t, v, `"data":[{"id":1},{"id":2}]`)
+
+	assert.Panics(t, func() { ErrorWithData(http.StatusOK, "", "", nil) })
+}
+
 func TestNewSuccess(t *testing.T) {
 	t.Run("when status code isnt a http successful status code", func(t *testing.T) {
 		data := struct{ ID string }{ID: "hello world"}
 		expected := &APIResponse{
 			StatusCode: http.StatusOK,
+			Version:    EnvelopeVersion,
 			Success:    true,
 			Message:    "new-success",
 			ErrorCode:  nil,
@@ -116,3 +145,365 @@ func TestNewSuccess(t *testing.T) {
 		})
 	})
 }
+
+func TestAPIResponse_AddWarning(t *testing.T) {
+	rsp := OK("done", nil).
+		AddWarning("parameter 'limit' is deprecated").
+		AddWarning("parameter 'sort' is ignored")
+
+	assert.True(t, rsp.Success)
+	assert.Equal(t, http.StatusOK, rsp.StatusCode)
+
+	v, err := rsp.ToJson()
+	assert.NoError(t, err)
+
+	got, err := FromJsonToAPIResponse([]byte(v))
+	assert.NoError(t, err)
+	assert.True(t, got.Success)
+	assert.Equal(t, []string{"parameter 'limit' is deprecated", "parameter 'sort' is ignored"}, got.Warnings)
+}
+
+func TestAPIResponse_WithDataType(t *testing.T) {
+	rsp := OK("", map[string]any{"orderId": "o_1"}).WithDataType("order.created")
+
+	v, err := rsp.ToJson()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful","data":{"orderId":"o_1"},"dataType":"order.created"}`, v)
+
+	got, err := FromJsonToAPIResponse([]byte(v))
+	assert.NoError(t, err)
+	assert.Equal(t, "order.created", got.DataType)
+}
+
+func TestAPIResponse_AsDryRun(t *testing.T) {
+	v, err := Created("", nil).AsDryRun().ToJson()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful","dryRun":true}`, v)
+
+	got, err := FromJsonToAPIResponse([]byte(v))
+	assert.NoError(t, err)
+	assert.True(t, got.DryRun)
+}
+
+func TestAPIResponse_WithLogField(t *testing.T) {
+	rsp := OK("", nil).WithLogField("user_id", 42).WithLogField("tenant", "acme")
+	assert.Equal(t, []slog.Attr{slog.Any("user_id", 42), slog.String("tenant", "acme")}, rsp.LogAttrs())
+
+	v, err := rsp.ToJson()
+	assert.NoError(t, err)
+	assert.NotContains(t, v, "acme")
+}
+
+func TestEnvelopeVersion(t *testing.T) {
+	v, err := OK("", nil).ToJson()
+	assert.NoError(t, err)
+
+	got, err := FromJsonToAPIResponse([]byte(v))
+	assert.NoError(t, err)
+	assert.Equal(t, EnvelopeVersion, got.Version)
+}
+
+func TestListWithStatus(t *testing.T) {
+	t.Run("custom 2xx status", func(t *testing.T) {
+		meta := map[string]any{"page": 1}
+		got := ListWithStatus(http.StatusPartialContent, "", []int{1, 2}, meta)
+
+		assert.Equal(t, http.StatusPartialContent, got.StatusCode)
+		assert.True(t, got.Success)
+		assert.Equal(t, "Request was successful", got.Message)
+		assert.Equal(t, meta, got.Meta)
+	})
+
+	t.Run("when status code isnt a 2xx status code", func(t *testing.T) {
+		assert.Panics(t, func() { ListWithStatus(http.StatusFound, "", nil, nil) })
+		assert.Panics(t, func() { ListWithStatus(http.StatusNotFound, "", nil, nil) })
+	})
+}
+
+func TestListCounted(t *testing.T) {
+	assert.Equal(t, map[string]any{"count": 3}, ListCounted("", []string{"a", "b", "c"}).Meta)
+	assert.Equal(t, map[string]any{"count": 0}, ListCounted("", []int(nil)).Meta)
+	assert.Equal(t, map[string]any{"count": 2}, ListCounted("", [2]int{}).Meta)
+
+	for _, data := range []any{map[string]int{"a": 1}, "not a list", nil} {
+		got := ListCounted("", data)
+		assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+		assert.Equal(t, "INVALID_LIST_DATA", *got.ErrorCode)
+		assert.Nil(t, got.Data)
+	}
+}
+
+func TestEmptyResult(t *testing.T) {
+	v, err := EmptyResult("").ToJson()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","success":true,"message":"No results found","data":[],"meta":{"totalItems":0}}`, v)
+}
+
+func TestForStatus(t *testing.T) {
+	t.Run("known error codes use their helper", func(t *testing.T) {
+		assert.Equal(t, NotFound("", "USR_404"), ForStatus(http.StatusNotFound, "", "USR_404", nil))
+		assert.Equal(t, "Request failed validation", ForStatus(http.StatusUnprocessableEntity, "", "", nil).Message)
+	})
+
+	t.Run("other error codes", func(t *testing.T) {
+		got := ForStatus(http.StatusTooManyRequests, "", "", map[string]int{"limit": 10})
+		assert.Equal(t, http.StatusTooManyRequests, got.StatusCode)
+		assert.Equal(t, "Too Many Requests", got.Message)
+		assert.False(t, got.Success)
+		assert.Equal(t, map[string]int{"limit": 10}, got.Data)
+	})
+
+	t.Run("success codes", func(t *testing.T) {
+		assert.Equal(t, Created("", "data"), ForStatus(http.StatusCreated, "", "", "data"))
+	})
+}
+
+func TestFromStatus(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		message    string
+		errorCode  string
+		success    bool
+	}{
+		{http.StatusOK, "OK", "", true},
+		{http.StatusFound, "Found", "", false},
+		{http.StatusNotFound, "Not Found", "NOT_FOUND", false},
+		{http.StatusRequestURITooLong, "Request URI Too Long", "REQUEST_URI_TOO_LONG", false},
+		{http.StatusTeapot, "I'm a teapot", "IM_A_TEAPOT", false},
+		{599, "Status 599", "STATUS_599", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got := FromStatus(tt.statusCode)
+			assert.Equal(t, tt.statusCode, got.StatusCode)
+			assert.Equal(t, tt.message, got.Message)
+			assert.Equal(t, tt.success, got.Success)
+			if tt.errorCode == "" {
+				assert.Nil(t, got.ErrorCode)
+			} else {
+				assert.Equal(t, tt.errorCode, *got.ErrorCode)
+			}
+		})
+	}
+}
+
+func TestMaxMessageLength(t *testing.T) {
+	defer func(v int) { MaxMessageLength = v }(MaxMessageLength)
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		MaxMessageLength = 0
+		msg := strings.Repeat("a", 5000)
+		assert.Equal(t, msg, InternalServerError(msg, "").Message)
+	})
+
+	t.Run("truncates with ellipsis", func(t *testing.T) {
+		MaxMessageLength = 10
+		got := InternalServerError("upstream exploded badly", "").Message
+		assert.Equal(t, "upstrea...", got)
+	})
+
+	t.Run("does not cut mid-rune", func(t *testing.T) {
+		MaxMessageLength = 8
+		got := BadRequest("héllo wörld", "").Message
+		assert.True(t, utf8.ValidString(got))
+		assert.LessOrEqual(t, len(got), 8)
+		assert.Equal(t, "héll...", got)
+	})
+}
+
+func TestConflictResource(t *testing.T) {
+	got := ConflictResource("user", "42")
+
+	assert.Equal(t, http.StatusConflict, got.StatusCode)
+	assert.Equal(t, "user with id '42' already exists", got.Message)
+	assert.Equal(t, map[string]any{"resource": "user", "id": "42"}, got.Details)
+}
+
+func TestAPIResponse_String(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		assert.Equal(t, `[404] error msg="user not found" code=USR_404`, NotFound("user not found", "USR_404").String())
+	})
+
+	t.Run("success with slice data", func(t *testing.T) {
+		assert.Equal(t, `[200] success msg="listed" data=[]int(len=3)`, OK("listed", []int{1, 2, 3}).String())
+	})
+
+	t.Run("success with struct data", func(t *testing.T) {
+		data := struct{ ID string }{ID: "1"}
+		assert.Equal(t, `[201] success msg="made" data=struct { ID string }`, Created("made", data).String())
+	})
+
+	t.Run("through fmt", func(t *testing.T) {
+		rsp := NotFound("user not found", "USR_404")
+		assert.Equal(t, `[404] error msg="user not found" code=USR_404`, fmt.Sprintf("%+v", rsp))
+		assert.Equal(t, "user not found", fmt.Sprintf("%v", rsp))
+		assert.Equal(t, `"user not found"`, fmt.Sprintf("%q", rsp))
+		assert.Equal(t, "lookup: user not found", fmt.Errorf("lookup: %w", rsp).Error())
+		assert.Contains(t, fmt.Sprintf("%#v", rsp), `Message:"user not found"`)
+	})
+}
+
+func TestAPIResponse_AppendJSON(t *testing.T) {
+	rsp := OK("fetched", map[string]any{"id": 1, "html": "<b>"})
+
+	want, err := rsp.ToJson()
+	assert.NoError(t, err)
+
+	got, err := rsp.AppendJSON([]byte("prefix:"))
+	assert.NoError(t, err)
+	assert.Equal(t, "prefix:"+want, string(got))
+
+	t.Run("marshal error leaves dst unchanged", func(t *testing.T) {
+		dst := []byte("x")
+		got, err := OK("", make(chan int)).AppendJSON(dst)
+		assert.Error(t, err)
+		assert.Equal(t, dst, got)
+	})
+}
+
+func BenchmarkAPIResponse_ToJson(b *testing.B) {
+	rsp := OK("fetched", struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}{ID: 1, Name: "john"})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := rsp.ToJson(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAPIResponse_AppendJSON(b *testing.B) {
+	rsp := OK("fetched", struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}{ID: 1, Name: "john"})
+	buf := make([]byte, 0, 512)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = rsp.AppendJSON(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestUnauthorizedWithChallenge(t *testing.T) {
+	got := UnauthorizedWithChallenge("Bearer", "api", "invalid_token")
+	assert.Equal(t, http.StatusUnauthorized, got.StatusCode)
+	assert.Equal(t, "invalid_token", *got.ErrorCode)
+	assert.Equal(t, `Bearer realm="api", error="invalid_token"`, got.Headers.Get("WWW-Authenticate"))
+
+	got = UnauthorizedWithChallenge("Basic", `my "realm"`, "")
+	assert.Equal(t, `Basic realm="my \"realm\""`, got.Headers.Get("WWW-Authenticate"))
+}
+
+func TestPaymentRequired(t *testing.T) {
+	got := PaymentRequired("", "QUOTA_EXCEEDED").WithUpgradeURL("https://app.test/upgrade")
+
+	assert.Equal(t, http.StatusPaymentRequired, got.StatusCode)
+	assert.Equal(t, "Payment is required to perform the requested action", got.Message)
+	assert.Equal(t, map[string]any{"upgradeUrl": "https://app.test/upgrade"}, got.Details)
+}
+
+func TestAPIResponse_WithActionLink(t *testing.T) {
+	got := Forbidden("", "EMAIL_UNVERIFIED").
+		WithActionLink("Verify email", "/account/verify").
+		WithActionLink("Contact support", "/support")
+
+	v, err := got.ToJson()
+	assert.NoError(t, err)
+	assert.Contains(t, v, `"details":{"actions":[{"label":"Verify email","href":"/account/verify"},{"label":"Contact support","href":"/support"}]}`)
+}
+
+func TestUnavailableForLegalReasons(t *testing.T) {
+	got := UnavailableForLegalReasons("", "GEO_BLOCKED")
+
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, got.StatusCode)
+	assert.False(t, got.Success)
+	assert.Equal(t, "Requested resource is unavailable for legal reasons", got.Message)
+}
+
+func TestStrictStatusCodes(t *testing.T) {
+	defer func(v bool) { StrictStatusCodes = v }(StrictStatusCodes)
+	StrictStatusCodes = false
+
+	t.Run("error with a success code becomes 500", func(t *testing.T) {
+		got := Error(http.StatusOK, "oops", "")
+		assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+		assert.False(t, got.Success)
+	})
+
+	t.Run("success with an error code becomes 200", func(t *testing.T) {
+		got := Success(http.StatusNotFound, "", nil)
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+		assert.True(t, got.Success)
+	})
+
+	t.Run("list with a non-2xx code becomes 200", func(t *testing.T) {
+		got := ListWithStatus(http.StatusFound, "", nil, nil)
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+	})
+}
+
+func TestIsSuccessStatus(t *testing.T) {
+	assert.True(t, OK("", nil).Success)
+	assert.False(t, Success(http.StatusFound, "", nil).Success)
+
+	defer func(v func(int) bool) { IsSuccessStatus = v }(IsSuccessStatus)
+	IsSuccessStatus = func(statusCode int) bool { return statusCode < http.StatusBadRequest }
+	assert.True(t, Success(http.StatusFound, "", nil).Success)
+}
+
+func TestRequireErrorCode(t *testing.T) {
+	defer func(v bool) { RequireErrorCode = v }(RequireErrorCode)
+	RequireErrorCode = true
+
+	assert.PanicsWithValue(t, "response error: cant set an error response without an error code", func() {
+		NotFound("user not found", " ")
+	})
+	assert.NotPanics(t, func() { NotFound("user not found", "USR_404") })
+	assert.NotPanics(t, func() { OK("", nil) })
+
+	t.Run("internal constructions carry a code", func(t *testing.T) {
+		defer func(v func(error) *APIResponse) { ErrorMapper = v }(ErrorMapper)
+		ErrorMapper = func(error) *APIResponse { return nil }
+		failing := func(*http.Request) (any, error) { return nil, errors.New("boom") }
+
+		helpers := map[string]func(){
+			"AutoError timeout":    func() { AutoError(context.DeadlineExceeded) },
+			"AutoError canceled":   func() { AutoError(context.Canceled) },
+			"AutoError no rows":    func() { AutoError(sql.ErrNoRows) },
+			"AutoError permission": func() { AutoError(os.ErrPermission) },
+			"AutoError other":      func() { AutoError(errors.New("boom")) },
+			"FromError":            func() { FromError(errors.New("boom")) },
+			"FromOzzoErrors":       func() { FromOzzoErrors(errors.New("boom")) },
+			"FromValidationErrors": func() { FromValidationErrors(errors.New("boom")) },
+			"ForStatus":            func() { ForStatus(http.StatusTooManyRequests, "", "", nil) },
+			"FromStruct":           func() { FromStruct(http.StatusNotFound, nil) },
+			"Health":               func() { Health(map[string]error{"db": errors.New("down")}) },
+			"ApplyMergePatch":      func() { ApplyMergePatch(make(chan int), []byte(`{}`)) },
+			"Aggregate": func() {
+				Aggregate(map[string]func() (any, error){"user": func() (any, error) { return nil, errors.New("down") }}, "user")
+			},
+			"WrapHandler": func() {
+				WrapHandler(failing).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			},
+		}
+		for name, helper := range helpers {
+			assert.NotPanics(t, helper, name)
+		}
+	})
+}
+
+func TestCORSForbidden(t *testing.T) {
+	got := CORSForbidden("https://evil.test")
+
+	assert.Equal(t, http.StatusForbidden, got.StatusCode)
+	assert.Equal(t, "Origin 'https://evil.test' is not allowed by the CORS policy", got.Message)
+	assert.Equal(t, "CORS_DENIED", *got.ErrorCode)
+	assert.Equal(t, map[string]any{"origin": "https://evil.test"}, got.Details)
+}
@@ -0,0 +1,48 @@
+package response
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// WithBinary embeds a small binary blob (e.g. a generated QR code) in Data under
+// name, as an object holding the base64-encoded `data` and its `contentType`,
+// sniffed with http.DetectContentType. A nil Data becomes a map; an existing
+// non-map Data is converted through JSON, keeping its keys when it is an object.
+// Data that isn't an object (e.g. a list) is never replaced: the blob is dropped and
+// a warning logged. Clients read the blob back with Binary.
+func (r *APIResponse) WithBinary(name string, data []byte) *APIResponse {
+	m, ok := asObject(r.Data)
+	if !ok {
+		slog.Warn("response error: cant embed a binary in data that is not an object",
+			"name", name, "type", fmt.Sprintf("%T", r.Data))
+		return r
+	}
+
+	m[name] = map[string]any{
+		"contentType": http.DetectContentType(data),
+		"data":        base64.StdEncoding.EncodeToString(data),
+	}
+	r.Data = m
+	return r
+}
+
+// Binary decodes a blob embedded with WithBinary, returning its bytes and content type.
+func (r *APIResponse) Binary(name string) ([]byte, string, error) {
+	data, _ := r.Data.(map[string]any)
+	blob, ok := data[name].(map[string]any)
+	if !ok {
+		return nil, "", fmt.Errorf("response: no binary %q in data", name)
+	}
+
+	encoded, _ := blob["data"].(string)
+	contentType, _ := blob["contentType"].(string)
+
+	b, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", fmt.Errorf("response: binary %q: %w", name, err)
+	}
+	return b, contentType, nil
+}
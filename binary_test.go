@@ -0,0 +1,47 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_WithBinary(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	t.Run("round-trips through json", func(t *testing.T) {
+		rsp := OK("", map[string]any{"id": 1}).WithBinary("qr", png)
+
+		b, err := json.Marshal(rsp)
+		assert.NoError(t, err)
+		decoded, err := FromJsonToAPIResponse(b)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), decoded.Data.(map[string]any)["id"])
+
+		blob, contentType, err := decoded.Binary("qr")
+		assert.NoError(t, err)
+		assert.Equal(t, png, blob)
+		assert.Equal(t, "image/png", contentType)
+	})
+
+	t.Run("non-object data is kept", func(t *testing.T) {
+		rsp := OK("", []int{1, 2}).WithBinary("qr", png)
+		assert.Equal(t, []int{1, 2}, rsp.Data)
+
+		rsp = OK("", struct {
+			ID int `json:"id"`
+		}{ID: 1}).WithBinary("qr", png)
+		assert.Equal(t, float64(1), rsp.Data.(map[string]any)["id"])
+		assert.Contains(t, rsp.Data, "qr")
+	})
+
+	t.Run("missing or malformed blob", func(t *testing.T) {
+		_, _, err := OK("", nil).Binary("qr")
+		assert.ErrorContains(t, err, `no binary "qr"`)
+
+		rsp := OK("", map[string]any{"qr": map[string]any{"data": "!!"}})
+		_, _, err = rsp.Binary("qr")
+		assert.ErrorContains(t, err, `binary "qr"`)
+	})
+}
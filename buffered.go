@@ -0,0 +1,98 @@
+package response
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// BufferedWriter is an http.ResponseWriter that buffers the status, headers and body
+// instead of sending them, so middleware can inspect or rewrite a response written by
+// a downstream handler before emitting it with Flush. The zero value is ready to use.
+type BufferedWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+// Header returns the buffered header map.
+func (b *BufferedWriter) Header() http.Header {
+	if b.header == nil {
+		b.header = make(http.Header)
+	}
+	return b.header
+}
+
+// WriteHeader records the status code. Only the first call has an effect.
+func (b *BufferedWriter) WriteHeader(statusCode int) {
+	if b.statusCode == 0 {
+		b.statusCode = statusCode
+	}
+}
+
+// Write appends to the buffered body.
+func (b *BufferedWriter) Write(p []byte) (int, error) {
+	if b.statusCode == 0 {
+		b.statusCode = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// StatusCode returns the buffered status code, 200 if none was written.
+func (b *BufferedWriter) StatusCode() int {
+	if b.statusCode == 0 {
+		return http.StatusOK
+	}
+	return b.statusCode
+}
+
+// Body returns the buffered body.
+func (b *BufferedWriter) Body() []byte {
+	return b.body.Bytes()
+}
+
+// Response decodes the buffered body as an APIResponse, with StatusCode and Headers
+// set from the buffered status and headers.
+func (b *BufferedWriter) Response() (*APIResponse, error) {
+	rsp, err := FromJsonToAPIResponse(b.body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	rsp.StatusCode = b.StatusCode()
+	rsp.Headers = b.header.Clone()
+	return rsp, nil
+}
+
+// SetResponse replaces the buffered status and body with r, keeping the buffered
+// headers. Headers set on r are merged in, and Content-Length is updated to the new
+// body.
+func (b *BufferedWriter) SetResponse(r *APIResponse) error {
+	body, err := r.ToJson()
+	if err != nil {
+		return err
+	}
+
+	body += "\n"
+	r.writeHeaders(b)
+	b.Header().Set("Content-Type", "application/json")
+	b.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	b.statusCode = r.httpStatus()
+	b.body.Reset()
+	b.body.WriteString(body)
+	return nil
+}
+
+// Flush emits the buffered headers, status and body to w. The headers are copied, so
+// later changes to either side don't affect the other.
+//
+// As the whole response is held until Flush, BufferedWriter doesn't suit streamed
+// responses; it implements neither http.Flusher nor Unwrap.
+func (b *BufferedWriter) Flush(w http.ResponseWriter) error {
+	for key, values := range b.header {
+		w.Header()[key] = slices.Clone(values)
+	}
+	w.WriteHeader(b.StatusCode())
+	_, err := w.Write(b.body.Bytes())
+	return err
+}
@@ -0,0 +1,76 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedWriter(t *testing.T) {
+	t.Run("buffers until flushed", func(t *testing.T) {
+		buf := &BufferedWriter{}
+		assert.NoError(t, NotFound("missing", "NF").SetHeader("X-Trace", "t1").Write(buf))
+
+		rsp, err := buf.Response()
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rsp.StatusCode)
+		assert.Equal(t, "missing", rsp.Message)
+		assert.Equal(t, "t1", rsp.Headers.Get("X-Trace"))
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, buf.Flush(rec))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "t1", rec.Header().Get("X-Trace"))
+		assert.Equal(t, string(buf.Body()), rec.Body.String())
+	})
+
+	t.Run("replace the buffered response", func(t *testing.T) {
+		buf := &BufferedWriter{}
+		buf.WriteHeader(http.StatusInternalServerError)
+		_, _ = buf.Write([]byte("panic: nil map"))
+
+		assert.NoError(t, buf.SetResponse(InternalServerError("", "INTERNAL")))
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, buf.Flush(rec))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"version":"1","success":false,"message":"Something went wrong on our end.","errorCode":"INTERNAL"}`, rec.Body.String())
+	})
+
+	t.Run("replacing a written response with a longer one", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			buf := &BufferedWriter{}
+			assert.NoError(t, NotFound("", "").Write(buf))
+			assert.NoError(t, buf.SetResponse(NotFound("user 42 was not found in the directory", "USER_NOT_FOUND")))
+			assert.NoError(t, buf.Flush(w))
+		}))
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		got, err := FromHTTPResponse(resp)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, got.StatusCode)
+		assert.Equal(t, "USER_NOT_FOUND", *got.ErrorCode)
+	})
+
+	t.Run("flush copies headers", func(t *testing.T) {
+		buf := &BufferedWriter{}
+		buf.Header().Set("X-Trace", "a")
+		rec := httptest.NewRecorder()
+		assert.NoError(t, buf.Flush(rec))
+
+		rec.Header()["X-Trace"][0] = "b"
+		assert.Equal(t, "a", buf.Header().Get("X-Trace"))
+	})
+
+	t.Run("defaults to 200", func(t *testing.T) {
+		buf := &BufferedWriter{}
+		assert.Equal(t, http.StatusOK, buf.StatusCode())
+	})
+}
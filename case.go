@@ -0,0 +1,132 @@
+package response
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// CaseStyle is a naming convention for object keys, see RecaseDataKeys.
+type CaseStyle int
+
+const (
+	CamelCase CaseStyle = iota // e.g. "createdAt"
+	SnakeCase                  // e.g. "created_at"
+)
+
+// RecaseDataKeys renames every object key in Data, including in nested objects and
+// arrays, to the given style, e.g. to pass snake_case database rows straight into a
+// camelCase API. Words are split on underscores, hyphens and case changes, keeping
+// acronyms together ("userID" becomes "user_id", "user_id" becomes "userId").
+//
+// When keys of one object collide after renaming ("user_id" and "userId"), the key
+// already in the target style wins, then the first key in sorted order; the others
+// are dropped.
+//
+// Data is replaced by its generic JSON form (maps, slices, json.Number, ...), so large
+// integer IDs keep their precision. When Data cannot be marshaled the response is
+// left unchanged.
+func (r *APIResponse) RecaseDataKeys(style CaseStyle) *APIResponse {
+	if r.Data == nil {
+		return r
+	}
+
+	data, err := normalizeJSONExact(r.Data)
+	if err != nil {
+		return r
+	}
+	r.Data = recaseKeys(data, style)
+	return r
+}
+
+func recaseKeys(v any, style CaseStyle) any {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		// Keys already in style go first, so they win collisions.
+		slices.SortFunc(keys, func(a, b string) int {
+			aStyled, bStyled := recase(a, style) == a, recase(b, style) == b
+			if aStyled != bStyled {
+				if aStyled {
+					return -1
+				}
+				return 1
+			}
+			return strings.Compare(a, b)
+		})
+
+		out := make(map[string]any, len(v))
+		for _, key := range keys {
+			renamed := recase(key, style)
+			if _, taken := out[renamed]; !taken {
+				out[renamed] = recaseKeys(v[key], style)
+			}
+		}
+		return out
+	case []any:
+		for i := range v {
+			v[i] = recaseKeys(v[i], style)
+		}
+	}
+	return v
+}
+
+// recase converts key to style.
+func recase(key string, style CaseStyle) string {
+	words := splitWords(key)
+	if len(words) == 0 {
+		return key
+	}
+
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if style == CamelCase && i > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		words[i] = word
+	}
+
+	if style == SnakeCase {
+		return strings.Join(words, "_")
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits s on separators and case changes: "HTTPServer_id" yields
+// ["HTTP", "Server", "id"].
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
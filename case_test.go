@@ -0,0 +1,66 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_RecaseDataKeys(t *testing.T) {
+	rows := []map[string]any{{
+		"user_id":    1,
+		"created_at": "2024-01-01",
+		"address":    map[string]any{"postal_code": "100001", "tags": []any{map[string]any{"tag_name": "home"}}},
+	}}
+
+	got := List("", rows, nil).RecaseDataKeys(CamelCase)
+	assert.Equal(t, []any{map[string]any{
+		"userId":    json.Number("1"),
+		"createdAt": "2024-01-01",
+		"address":   map[string]any{"postalCode": "100001", "tags": []any{map[string]any{"tagName": "home"}}},
+	}}, got.Data)
+
+	got = got.RecaseDataKeys(SnakeCase)
+	assert.Equal(t, []any{map[string]any{
+		"user_id":    json.Number("1"),
+		"created_at": "2024-01-01",
+		"address":    map[string]any{"postal_code": "100001", "tags": []any{map[string]any{"tag_name": "home"}}},
+	}}, got.Data)
+
+	assert.Nil(t, OK("", nil).RecaseDataKeys(CamelCase).Data)
+
+	t.Run("large integers keep their precision", func(t *testing.T) {
+		got := OK("", map[string]any{"user_id": uint64(9007199254740993)}).RecaseDataKeys(CamelCase)
+		b, err := json.Marshal(got.Data)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"userId":9007199254740993}`, string(b))
+	})
+
+	t.Run("colliding keys", func(t *testing.T) {
+		data := map[string]any{"user_id": 1, "userId": 2, "USER_ID": 3}
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, map[string]any{"userId": json.Number("2")}, OK("", data).RecaseDataKeys(CamelCase).Data)
+		}
+		assert.Equal(t, map[string]any{"user_id": json.Number("1")}, OK("", data).RecaseDataKeys(SnakeCase).Data)
+	})
+}
+
+func TestRecase(t *testing.T) {
+	tests := []struct {
+		key, camel, snake string
+	}{
+		{"user_id", "userId", "user_id"},
+		{"userID", "userId", "user_id"},
+		{"HTTPServer", "httpServer", "http_server"},
+		{"item-count2", "itemCount2", "item_count2"},
+		{"v2Name", "v2Name", "v2_name"},
+		{"__", "__", "__"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.camel, recase(tt.key, CamelCase))
+			assert.Equal(t, tt.snake, recase(tt.key, SnakeCase))
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EqualExcept reports whether r and other are equal field-by-field, skipping the
+// fields named in ignore. A field may be named by its Go name (e.g. "Message")
+// or by its JSON key (e.g. "message").
+//
+// Data and Meta (and any other field holding an interface value) are compared
+// after JSON normalization, so a struct and the map[string]any obtained from
+// decoding it compare equal, while numbers are compared exactly. Headers derived
+// from an ignored field are ignored with it, e.g. `X-Request-ID` with RequestID.
+// This is useful in contract tests where volatile fields should not make otherwise
+// identical responses differ.
+func (r *APIResponse) EqualExcept(other *APIResponse, ignore ...string) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+
+	a := reflect.ValueOf(r).Elem()
+	b := reflect.ValueOf(other).Elem()
+	t := a.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if slices.Contains(ignore, field.Name) || slices.Contains(ignore, jsonFieldName(field)) {
+			continue
+		}
+
+		if field.Name == "Headers" {
+			if !reflect.DeepEqual(headersExcept(r.Headers, ignore), headersExcept(other.Headers, ignore)) {
+				return false
+			}
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Interface {
+			if !jsonEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+				return false
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// derivedHeaders maps fields to the headers set from them, see WithRequestID.
+var derivedHeaders = map[string]string{
+	"RequestID": "X-Request-ID",
+	"requestId": "X-Request-ID",
+}
+
+// headersExcept returns h without the headers derived from the fields in ignore.
+func headersExcept(h http.Header, ignore []string) http.Header {
+	h = h.Clone()
+	for _, name := range ignore {
+		if key, ok := derivedHeaders[name]; ok {
+			h.Del(key)
+		}
+	}
+	if len(h) == 0 {
+		return nil
+	}
+	return h
+}
+
+// jsonFieldName returns the JSON key of a struct field, falling back to the
+// Go field name when no json tag is present.
+func jsonFieldName(field reflect.StructField) string {
+	name, _ := parseJSONTag(field)
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// parseJSONTag splits the json tag of field into its name, empty when unnamed, and
+// its options such as "omitempty" or "string".
+func parseJSONTag(field reflect.StructField) (string, []string) {
+	name, opts, ok := strings.Cut(field.Tag.Get("json"), ",")
+	if !ok {
+		return name, nil
+	}
+	return name, strings.Split(opts, ",")
+}
+
+// jsonEqual compares two values by their JSON representation.
+func jsonEqual(a, b any) bool {
+	na, errA := normalizeJSONExact(a)
+	nb, errB := normalizeJSONExact(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return reflect.DeepEqual(na, nb)
+}
+
+// normalizeJSON round-trips v through JSON, producing the generic
+// representation (maps, slices, float64, string, bool, nil).
+func normalizeJSON(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// normalizeJSONExact is normalizeJSON keeping numbers as json.Number, so integers
+// beyond float64 precision (e.g. 64-bit IDs) survive the round trip.
+func normalizeJSONExact(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// InternalFields lists the fields PublicView clears before a response is forwarded
+// to public clients. Fields are named by their Go name or JSON key, e.g. "errorCode".
+var InternalFields []string
+
+// PublicView returns a copy of the response with every field listed in
+// InternalFields reset to its zero value. Headers Write derives from a cleared field
+// are dropped too: `X-Request-ID` with RequestID, and the `rel="help"` Link with
+// HelpURL, or with ErrorCode when HelpURL is the doc registered for that code. The
+// receiver is left unchanged, so an internal response can be forwarded at the edge
+// without leaking internal details.
+func (r *APIResponse) PublicView() *APIResponse {
+	view := r.clone()
+	v := reflect.ValueOf(view).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if slices.Contains(InternalFields, field.Name) || slices.Contains(InternalFields, jsonFieldName(field)) {
+			v.Field(i).SetZero()
+		}
+	}
+
+	if view.RequestID == "" && r.RequestID != "" {
+		view.Headers.Del("X-Request-ID")
+	}
+	if view.ErrorCode == nil && r.ErrorCode != nil && view.HelpURL != "" && view.HelpURL == errorDoc(*r.ErrorCode) {
+		view.HelpURL = ""
+	}
+	if view.HelpURL == "" && r.HelpURL != "" && view.Headers != nil {
+		view.Headers.Del("Link")
+		for _, link := range r.Headers.Values("Link") {
+			if !strings.Contains(link, `rel="help"`) {
+				view.Headers.Add("Link", link)
+			}
+		}
+	}
+
+	return view
+}
+
+// DiffEnvelope compares the top-level keys of two serialized responses and reports
+// keys that were added, removed, or whose JSON type changed, e.g.
+// `changed "meta": object -> array`. It works on the wire format, so it catches real
+// compatibility breaks such as a renamed or dropped envelope field. The result is
+// sorted and empty when the envelopes are compatible.
+func DiffEnvelope(oldJSON, newJSON []byte) ([]string, error) {
+	var oldFields, newFields map[string]json.RawMessage
+	if err := json.Unmarshal(oldJSON, &oldFields); err != nil {
+		return nil, fmt.Errorf("old envelope: %w", err)
+	}
+	if err := json.Unmarshal(newJSON, &newFields); err != nil {
+		return nil, fmt.Errorf("new envelope: %w", err)
+	}
+
+	var diffs []string
+	for key, oldValue := range oldFields {
+		newValue, ok := newFields[key]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("removed %q", key))
+			continue
+		}
+		if oldType, newType := jsonType(oldValue), jsonType(newValue); oldType != newType {
+			diffs = append(diffs, fmt.Sprintf("changed %q: %s -> %s", key, oldType, newType))
+		}
+	}
+	for key := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			diffs = append(diffs, fmt.Sprintf("added %q", key))
+		}
+	}
+
+	slices.Sort(diffs)
+	return diffs, nil
+}
+
+// jsonType names the JSON type of a raw value.
+func jsonType(raw json.RawMessage) string {
+	switch b := bytes.TrimSpace(raw); {
+	case len(b) == 0:
+		return "empty"
+	case b[0] == '{':
+		return "object"
+	case b[0] == '[':
+		return "array"
+	case b[0] == '"':
+		return "string"
+	case b[0] == 't' || b[0] == 'f':
+		return "boolean"
+	case b[0] == 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,141 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_EqualExcept(t *testing.T) {
+	t.Run("data compared after json normalization", func(t *testing.T) {
+		a := OK("fetched", struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}{ID: 1, Name: "john"})
+		b := OK("fetched", map[string]any{"id": 1, "name": "john"})
+
+		assert.True(t, a.EqualExcept(b))
+	})
+
+	t.Run("ignored fields are skipped by go or json name", func(t *testing.T) {
+		a := NotFound("user not found", "USR_404")
+		b := NotFound("no such user", "USR_404")
+
+		assert.False(t, a.EqualExcept(b))
+		assert.True(t, a.EqualExcept(b, "Message"))
+		assert.True(t, a.EqualExcept(b, "message"))
+	})
+
+	t.Run("differing non-ignored field", func(t *testing.T) {
+		a := NotFound("", "USR_404")
+		b := Error(http.StatusGone, a.Message, "USR_404")
+
+		assert.False(t, a.EqualExcept(b, "message"))
+		assert.True(t, a.EqualExcept(b, "StatusCode"))
+	})
+
+	t.Run("ignoring requestId ignores its header", func(t *testing.T) {
+		a := OK("", nil).WithRequestID("req-1").SetHeader("ETag", `"v1"`)
+		b := OK("", nil).WithRequestID("req-2").SetHeader("ETag", `"v1"`)
+
+		assert.False(t, a.EqualExcept(b))
+		assert.True(t, a.EqualExcept(b, "requestId"))
+		assert.True(t, a.EqualExcept(b, "RequestID"))
+		assert.False(t, a.EqualExcept(b.SetHeader("ETag", `"v2"`), "requestId"))
+	})
+
+	t.Run("64-bit ids compared exactly", func(t *testing.T) {
+		a := OK("", map[string]any{"id": int64(9007199254740993)})
+		b := OK("", map[string]any{"id": int64(9007199254740992)})
+		assert.False(t, a.EqualExcept(b))
+	})
+
+	t.Run("nil responses", func(t *testing.T) {
+		var a *APIResponse
+		assert.True(t, a.EqualExcept(nil))
+		assert.False(t, OK("", nil).EqualExcept(nil))
+	})
+}
+
+func TestAPIResponse_PublicView(t *testing.T) {
+	defer func(v []string) { InternalFields = v }(InternalFields)
+	InternalFields = []string{"errorCode", "Meta"}
+
+	internal := InternalServerError("db timeout", "DB_TIMEOUT_17")
+	internal.Meta = map[string]any{"traceId": "t-1"}
+
+	public := internal.PublicView()
+	assert.Nil(t, public.ErrorCode)
+	assert.Nil(t, public.Meta)
+	assert.Equal(t, internal.Message, public.Message)
+	assert.Equal(t, internal.StatusCode, public.StatusCode)
+
+	assert.Equal(t, "DB_TIMEOUT_17", *internal.ErrorCode)
+	assert.NotNil(t, internal.Meta)
+
+	t.Run("derived headers", func(t *testing.T) {
+		defer func(v []string) { InternalFields = v }(InternalFields)
+		InternalFields = []string{"requestId", "errorCode"}
+		RegisterErrorDoc("DB_TIMEOUT_18", "https://docs.example.com/errors/db-timeout")
+
+		internal := InternalServerError("db timeout", "DB_TIMEOUT_18").WithRequestID("trace-123")
+		internal.SetHeader("Link", `</status>; rel="status"`)
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, internal.PublicView().Write(rec))
+		assert.Empty(t, rec.Header().Get("X-Request-Id"))
+		assert.Equal(t, []string{`</status>; rel="status"`}, rec.Header().Values("Link"))
+		assert.NotContains(t, rec.Body.String(), "trace-123")
+		assert.NotContains(t, rec.Body.String(), "docs.example.com")
+
+		rec = httptest.NewRecorder()
+		assert.NoError(t, internal.Write(rec))
+		assert.Equal(t, "trace-123", rec.Header().Get("X-Request-Id"))
+		assert.Len(t, rec.Header().Values("Link"), 2)
+	})
+
+	t.Run("internal headers and help url", func(t *testing.T) {
+		defer func(v []string) { InternalFields = v }(InternalFields)
+		InternalFields = []string{"Headers", "helpUrl"}
+
+		internal := NotFound("", "")
+		internal.HelpURL = "https://docs.example.com/errors/not-found"
+		internal.SetHeader("Link", `</status>; rel="status"`)
+
+		var public *APIResponse
+		assert.NotPanics(t, func() { public = internal.PublicView() })
+		assert.Nil(t, public.Headers)
+		assert.Empty(t, public.HelpURL)
+	})
+}
+
+func TestDiffEnvelope(t *testing.T) {
+	t.Run("compatible", func(t *testing.T) {
+		diffs, err := DiffEnvelope(
+			[]byte(`{"success":true,"message":"a","data":{"id":1}}`),
+			[]byte(`{"success":false,"message":"b","data":{"id":2}}`),
+		)
+		assert.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("added removed and changed keys", func(t *testing.T) {
+		diffs, err := DiffEnvelope(
+			[]byte(`{"success":true,"message":"a","meta":{"page":1}}`),
+			[]byte(`{"status":true,"message":"a","meta":[1]}`),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			`added "status"`,
+			`changed "meta": object -> array`,
+			`removed "success"`,
+		}, diffs)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := DiffEnvelope([]byte(`{`), []byte(`{}`))
+		assert.ErrorContains(t, err, "old envelope")
+	})
+}
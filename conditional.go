@@ -0,0 +1,108 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CheckIfMatch enforces optimistic concurrency on mutating requests. It returns a
+// PreconditionFailed response when the request's `If-Match` header doesn't match
+// currentETag, and nil when it does or when the header is absent.
+//
+// Matching uses strong comparison, so weak tags (W/"...") never match. currentETag
+// may be given with or without surrounding quotes.
+func CheckIfMatch(req *http.Request, currentETag string) *APIResponse {
+	ifMatch := req.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil
+	}
+
+	current := quoteETag(currentETag)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if (tag == "*" && currentETag != "") || (tag == current && !strings.HasPrefix(tag, "W/")) {
+			return nil
+		}
+	}
+
+	return PreconditionFailed("", "ETAG_MISMATCH")
+}
+
+// quoteETag wraps etag in double quotes unless it is already quoted or weak.
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// WithETag makes Write emit the `ETag` header. etag is quoted when needed.
+func (r *APIResponse) WithETag(etag string) *APIResponse {
+	return r.SetHeader("ETag", quoteETag(etag))
+}
+
+// NotModified creates a response with (HTTP 304) code for conditional GETs. Write
+// emits only its headers, including the `ETag`, with no body and no Content-Type.
+func NotModified(etag string) *APIResponse {
+	return NewAPIResponse(http.StatusNotModified, IsSuccessStatus(http.StatusNotModified), "Not Modified", "", nil).WithETag(etag)
+}
+
+// ConditionalGet serves a GET whose result is identified by etag. When the request's
+// `If-None-Match` header matches etag, it returns NotModified without calling build,
+// sparing expensive work while the client's cache is valid. Otherwise it returns
+// OK with the result of build and the ETag set.
+//
+// Matching uses weak comparison, as If-None-Match requires, so W/"v1" matches "v1".
+func ConditionalGet(req *http.Request, etag string, build func() any) *APIResponse {
+	if ifNoneMatch := req.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		current := strings.TrimPrefix(quoteETag(etag), "W/")
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+				return NotModified(etag)
+			}
+		}
+	}
+
+	return OK("", build()).WithETag(etag)
+}
+
+// WithCache declares the caching policy of the response, which Write emits as the
+// `Cache-Control` header: `public, max-age=300` / `private, max-age=300`, or
+// `private, no-store` when maxAge is 0. Error responses are always sent with
+// `no-store` so failures are never cached.
+func (r *APIResponse) WithCache(maxAge time.Duration, public bool) *APIResponse {
+	if maxAge <= 0 {
+		return r.SetHeader("Cache-Control", "private, no-store")
+	}
+
+	visibility := "private"
+	if public {
+		visibility = "public"
+	}
+	return r.SetHeader("Cache-Control", fmt.Sprintf("%s, max-age=%d", visibility, int64(maxAge.Seconds())))
+}
+
+// WithVary adds headers to the `Vary` header Write emits, telling caches which
+// request headers select the representation, e.g. WithVary("Accept-Language") for
+// a response localized from the request. Headers already listed are skipped.
+// WriteFormat adds `Accept` by itself.
+func (r *APIResponse) WithVary(headers ...string) *APIResponse {
+	var vary []string
+	if existing := r.Headers.Get("Vary"); existing != "" {
+		for _, h := range strings.Split(existing, ",") {
+			vary = append(vary, strings.TrimSpace(h))
+		}
+	}
+
+	for _, h := range headers {
+		h = http.CanonicalHeaderKey(strings.TrimSpace(h))
+		if !slices.ContainsFunc(vary, func(v string) bool { return strings.EqualFold(v, h) }) {
+			vary = append(vary, h)
+		}
+	}
+	return r.SetHeader("Vary", strings.Join(vary, ", "))
+}
@@ -0,0 +1,124 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	request := func(ifMatch string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "/users/1", nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return req
+	}
+
+	t.Run("no header", func(t *testing.T) {
+		assert.Nil(t, CheckIfMatch(request(""), "v1"))
+	})
+
+	t.Run("matching etag", func(t *testing.T) {
+		assert.Nil(t, CheckIfMatch(request(`"v0", "v1"`), "v1"))
+		assert.Nil(t, CheckIfMatch(request(`"v1"`), `"v1"`))
+		assert.Nil(t, CheckIfMatch(request(`*`), "v1"))
+	})
+
+	t.Run("mismatched or weak etag", func(t *testing.T) {
+		got := CheckIfMatch(request(`"v0"`), "v1")
+		assert.Equal(t, http.StatusPreconditionFailed, got.StatusCode)
+		assert.False(t, got.Success)
+
+		assert.NotNil(t, CheckIfMatch(request(`W/"v1"`), `W/"v1"`))
+		assert.NotNil(t, CheckIfMatch(request(`*`), ""))
+	})
+}
+
+func TestNotModified(t *testing.T) {
+	rsp := NotModified("v7")
+	assert.Equal(t, http.StatusNotModified, rsp.StatusCode)
+	assert.False(t, rsp.Success)
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, rsp.Write(rec))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, `"v7"`, rec.Header().Get("ETag"))
+	assert.Empty(t, rec.Header().Get("Content-Type"))
+	assert.Empty(t, rec.Body.String())
+
+	t.Run("success follows IsSuccessStatus", func(t *testing.T) {
+		defer func(v func(int) bool) { IsSuccessStatus = v }(IsSuccessStatus)
+		IsSuccessStatus = func(statusCode int) bool { return statusCode < http.StatusBadRequest }
+		assert.True(t, NotModified("v7").Success)
+	})
+}
+
+func TestConditionalGet(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{"no header", "", http.StatusOK},
+		{"match", `"v1"`, http.StatusNotModified},
+		{"weak match in list", `"v0", W/"v1"`, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"mismatch", `"v0"`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			built := false
+			got := ConditionalGet(req, "v1", func() any {
+				built = true
+				return map[string]int{"id": 1}
+			})
+
+			assert.Equal(t, tt.wantStatus, got.StatusCode)
+			assert.Equal(t, tt.wantStatus == http.StatusOK, built)
+			assert.Equal(t, `"v1"`, got.Headers.Get("ETag"))
+		})
+	}
+}
+
+func TestAPIResponse_WithVary(t *testing.T) {
+	rsp := OK("", nil).WithVary("accept-language").WithVary("Accept", "Accept-Language")
+	assert.Equal(t, "Accept-Language, Accept", rsp.Headers.Get("Vary"))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.NoError(t, rsp.WriteFormat(rec, req))
+	assert.Equal(t, "Accept-Language, Accept", rec.Header().Get("Vary"))
+	assert.Equal(t, "Accept-Language, Accept", rsp.Headers.Get("Vary"))
+}
+
+func TestAPIResponse_WithCache(t *testing.T) {
+	tests := []struct {
+		name string
+		rsp  *APIResponse
+		want string
+	}{
+		{"public", OK("", nil).WithCache(5*time.Minute, true), "public, max-age=300"},
+		{"private", OK("", nil).WithCache(time.Minute, false), "private, max-age=60"},
+		{"no caching", OK("", nil).WithCache(0, true), "private, no-store"},
+		{"error forces no-store", NotFound("", "").WithCache(time.Hour, true), "no-store"},
+		{"error without a policy", NotFound("", ""), "no-store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			assert.NoError(t, tt.rsp.Write(rec))
+			assert.Equal(t, tt.want, rec.Header().Get("Cache-Control"))
+		})
+	}
+}
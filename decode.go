@@ -0,0 +1,307 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FromJsonLimited decodes a JSON byte array into an APIResponse struct, rejecting
+// input nested deeper than maxDepth objects/arrays. The envelope itself counts as
+// depth 1. A maxDepth of 0 or less disables the check.
+//
+// The depth is enforced by scanning tokens before the full unmarshal, which protects
+// clients from deeply nested payloads sent by untrusted intermediaries.
+func FromJsonLimited(b []byte, maxDepth int) (*APIResponse, error) {
+	if maxDepth > 0 {
+		if err := checkJSONDepth(b, maxDepth); err != nil {
+			return nil, err
+		}
+	}
+	return FromJsonToAPIResponse(b)
+}
+
+func checkJSONDepth(b []byte, maxDepth int) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	depth := 0
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > maxDepth {
+				return fmt.Errorf("body exceeds maximum JSON nesting depth of %d (at character %d)", maxDepth, dec.InputOffset())
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// FromHTTPResponse adapts a downstream *http.Response into an APIResponse, e.g.
+// when proxying. The body is read fully and closed.
+//
+// If the body is an APIResponse envelope it is decoded as such; otherwise the raw
+// body becomes Data (as raw JSON when valid, or as a string) and Success is derived
+// from the status code with IsSuccessStatus. In both cases StatusCode is taken from
+// resp.StatusCode.
+func FromHTTPResponse(resp *http.Response) (*APIResponse, error) {
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if isEnvelope(body) {
+		rsp, err := FromJsonToAPIResponse(body)
+		if err != nil {
+			return nil, err
+		}
+		rsp.StatusCode = resp.StatusCode
+		return rsp, nil
+	}
+
+	var data any
+	switch {
+	case len(bytes.TrimSpace(body)) == 0:
+		data = nil
+	case json.Valid(body):
+		data = json.RawMessage(body)
+	default:
+		data = string(body)
+	}
+
+	return NewAPIResponse(resp.StatusCode, IsSuccessStatus(resp.StatusCode), http.StatusText(resp.StatusCode), "", data), nil
+}
+
+// isEnvelope reports whether body is a JSON object shaped like an APIResponse.
+func isEnvelope(body []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+
+	_, hasSuccess := fields["success"]
+	_, hasMessage := fields["message"]
+	return hasSuccess && hasMessage
+}
+
+// DecodeQuery maps the request's query parameters into the fields of a struct T,
+// named by their `query` or `form` tag (or the field name when untagged; "-" skips
+// a field). Strings, bools, integers, floats, pointers to those and slices of those
+// (from repeated parameters) are supported.
+//
+// Values that fail to convert are reported as field errors in a ValidationFailed
+// response, e.g. "page must be an integer". T must be a struct type.
+func DecodeQuery[T any](r *http.Request) (T, *APIResponse) {
+	var out T
+
+	v := reflect.ValueOf(&out).Elem()
+	if v.Kind() != reflect.Struct {
+		panic("response error: DecodeQuery target must be a struct")
+	}
+
+	query := r.URL.Query()
+	var fieldErrors []FieldError
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := queryFieldName(field)
+		values, ok := query[name]
+		if name == "-" || !ok || len(values) == 0 {
+			continue
+		}
+
+		if err := setQueryValue(v.Field(i), values); err != nil {
+			fieldErrors = append(fieldErrors, NewFieldError(name, "invalid_type", name+" must be "+err.Error()))
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return out, ValidationFailed("Invalid query parameters", "INVALID_QUERY", fieldErrors...)
+	}
+	return out, nil
+}
+
+// DecodeBatch decodes a request body holding a JSON array of T, e.g. for bulk-create
+// endpoints. The array is streamed, so an oversized batch is rejected as soon as its
+// item maxItems+1 is seen. Each item implementing `Validate() error` (on T or *T) is
+// validated.
+//
+// A body that isn't a single JSON array yields a BadRequest with code INVALID_JSON.
+// More than maxItems items (when maxItems > 0) yield a ValidationFailed with code
+// BATCH_TOO_LARGE. Items that fail to decode or validate, or are null when T is a
+// pointer type, are reported together in a ValidationFailed with code INVALID_BATCH,
+// one field error per item keyed by its index, with a JSON Pointer such as "/2".
+func DecodeBatch[T any](r *http.Request, maxItems int) ([]T, *APIResponse) {
+	dec := json.NewDecoder(r.Body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, invalidBatch(err)
+	}
+
+	var items []T
+	var fieldErrors []FieldError
+	for i := 0; dec.More(); i++ {
+		if maxItems > 0 && i == maxItems {
+			return nil, ValidationFailed(fmt.Sprintf("Batch exceeds the limit of %d items", maxItems), "BATCH_TOO_LARGE")
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, invalidBatch(err)
+		}
+
+		var item T
+		if fieldError, ok := decodeBatchItem(raw, &item, strconv.Itoa(i)); !ok {
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+		items = append(items, item)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, invalidBatch(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, BadRequest("Invalid batch: unexpected data after the array", "INVALID_JSON")
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, ValidationFailed("Invalid batch items", "INVALID_BATCH", fieldErrors...)
+	}
+	if items == nil {
+		items = []T{}
+	}
+	return items, nil
+}
+
+// decodeBatchItem decodes raw into item and validates it, returning the field error
+// of the item at index when it is malformed, null or invalid.
+func decodeBatchItem[T any](raw json.RawMessage, item *T, index string) (FieldError, bool) {
+	if err := json.Unmarshal(raw, item); err != nil {
+		return NewFieldError(index, "invalid_type", "item "+index+" is malformed").WithPointer("/" + index), false
+	}
+
+	validator, ok := any(item).(interface{ Validate() error })
+	if !ok {
+		// T may itself be a pointer type implementing Validate.
+		if rv := reflect.ValueOf(*item); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return NewFieldError(index, "required", "item "+index+" is null").WithPointer("/" + index), false
+		}
+		if validator, ok = any(*item).(interface{ Validate() error }); !ok {
+			return FieldError{}, true
+		}
+	}
+	if err := validator.Validate(); err != nil {
+		return NewFieldError(index, "invalid", err.Error()).WithPointer("/" + index), false
+	}
+	return FieldError{}, true
+}
+
+// invalidBatch describes err, from decoding the batch array itself, as a BadRequest.
+func invalidBatch(err error) *APIResponse {
+	if _, details := IsJsonErrorGetDetails(err); details != nil {
+		return BadRequest("Invalid batch: "+details.Error(), "INVALID_JSON")
+	}
+	return BadRequest("Invalid batch: expected a JSON array", "INVALID_JSON")
+}
+
+func queryFieldName(field reflect.StructField) string {
+	for _, key := range []string{"query", "form"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(key), ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// setQueryValue converts values into v. The returned error describes the expected
+// type, e.g. "an integer".
+func setQueryValue(v reflect.Value, values []string) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := setQueryValue(slice.Index(i), []string{s}); err != nil {
+				return fmt.Errorf("a list where each item is %w", err)
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setQueryValue(elem.Elem(), values); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	s := values[0]
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.New("a number")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("a supported type (got %s)", v.Type())
+	}
+	return nil
+}
+
+// FromResponseBytes decodes a response body received alongside its HTTP status and
+// headers, e.g. in an SDK. StatusCode is set from status, Headers from header, and
+// RequestID from the `X-Request-ID` header when present.
+func FromResponseBytes(status int, header http.Header, body []byte) (*APIResponse, error) {
+	rsp, err := FromJsonToAPIResponse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp.StatusCode = status
+	rsp.Headers = header.Clone()
+	if id := header.Get("X-Request-ID"); id != "" {
+		rsp.RequestID = id
+	}
+	return rsp, nil
+}
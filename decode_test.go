@@ -0,0 +1,193 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromJsonLimited(t *testing.T) {
+	t.Run("within limit", func(t *testing.T) {
+		got, err := FromJsonLimited([]byte(`{"success":true,"message":"ok","data":{"items":[1,2]}}`), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", got.Message)
+	})
+
+	t.Run("exceeds limit", func(t *testing.T) {
+		deep := `{"success":true,"message":"ok","data":` + strings.Repeat("[", 50) + strings.Repeat("]", 50) + `}`
+		got, err := FromJsonLimited([]byte(deep), 10)
+		assert.Nil(t, got)
+		assert.ErrorContains(t, err, "maximum JSON nesting depth of 10")
+	})
+
+	t.Run("zero disables the limit", func(t *testing.T) {
+		deep := `{"success":true,"message":"ok","data":` + strings.Repeat("[", 50) + strings.Repeat("]", 50) + `}`
+		_, err := FromJsonLimited([]byte(deep), 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("malformed json", func(t *testing.T) {
+		_, err := FromJsonLimited([]byte(`{"success":`), 5)
+		assert.Error(t, err)
+	})
+}
+
+func TestFromHTTPResponse(t *testing.T) {
+	newResponse := func(status int, body string) *http.Response {
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	t.Run("envelope keeps the downstream status", func(t *testing.T) {
+		got, err := FromHTTPResponse(newResponse(http.StatusNotFound, `{"success":false,"message":"gone","errorCode":"NF"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, got.StatusCode)
+		assert.Equal(t, "gone", got.Message)
+		assert.Equal(t, "NF", *got.ErrorCode)
+	})
+
+	t.Run("non-envelope json is wrapped as data", func(t *testing.T) {
+		got, err := FromHTTPResponse(newResponse(http.StatusOK, `{"id":1}`))
+		assert.NoError(t, err)
+		assert.True(t, got.Success)
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+		assert.Equal(t, json.RawMessage(`{"id":1}`), got.Data)
+	})
+
+	t.Run("plain text error", func(t *testing.T) {
+		got, err := FromHTTPResponse(newResponse(http.StatusBadGateway, "upstream down"))
+		assert.NoError(t, err)
+		assert.False(t, got.Success)
+		assert.Equal(t, http.StatusBadGateway, got.StatusCode)
+		assert.Equal(t, "Bad Gateway", got.Message)
+		assert.Equal(t, "upstream down", got.Data)
+	})
+}
+
+func TestDecodeQuery(t *testing.T) {
+	type params struct {
+		Page    int      `query:"page"`
+		Search  string   `form:"q"`
+		Active  *bool    `query:"active"`
+		Tags    []string `query:"tag"`
+		Ignored string   `query:"-"`
+	}
+
+	t.Run("valid params", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users?page=2&q=jo&active=true&tag=a&tag=b&Ignored=x", nil)
+		got, rsp := DecodeQuery[params](req)
+
+		active := true
+		assert.Nil(t, rsp)
+		assert.Equal(t, params{Page: 2, Search: "jo", Active: &active, Tags: []string{"a", "b"}}, got)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users?page=two&active=maybe", nil)
+		_, rsp := DecodeQuery[params](req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rsp.StatusCode)
+		assert.Equal(t, []FieldError{
+			NewFieldError("page", "invalid_type", "page must be an integer"),
+			NewFieldError("active", "invalid_type", "active must be a boolean"),
+		}, rsp.Errors)
+	})
+
+	t.Run("non struct target", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.Panics(t, func() { DecodeQuery[int](req) })
+	})
+}
+
+type batchItem struct {
+	Name string `json:"name"`
+}
+
+func (i batchItem) Validate() error {
+	if i.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestDecodeBatch(t *testing.T) {
+	decode := func(body string, maxItems int) ([]batchItem, *APIResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+		return DecodeBatch[batchItem](req, maxItems)
+	}
+
+	t.Run("valid batch", func(t *testing.T) {
+		items, rsp := decode(`[{"name":"a"},{"name":"b"}]`, 2)
+		assert.Nil(t, rsp)
+		assert.Equal(t, []batchItem{{Name: "a"}, {Name: "b"}}, items)
+	})
+
+	t.Run("per-item errors", func(t *testing.T) {
+		_, rsp := decode(`[{"name":"a"},{"name":""},{"name":1}]`, 0)
+		assert.Equal(t, http.StatusUnprocessableEntity, rsp.StatusCode)
+		assert.Equal(t, "INVALID_BATCH", *rsp.ErrorCode)
+		assert.Equal(t, []FieldError{
+			NewFieldError("1", "invalid", "name is required").WithPointer("/1"),
+			NewFieldError("2", "invalid_type", "item 2 is malformed").WithPointer("/2"),
+		}, rsp.Errors)
+	})
+
+	t.Run("too many items", func(t *testing.T) {
+		_, rsp := decode(`[{"name":"a"},{"name":"b"}]`, 1)
+		assert.Equal(t, "BATCH_TOO_LARGE", *rsp.ErrorCode)
+		assert.Equal(t, "Batch exceeds the limit of 1 items", rsp.Message)
+	})
+
+	t.Run("too many items stops reading", func(t *testing.T) {
+		_, rsp := decode(`[{"name":"a"},{"name":"b"}, not even JSON`, 1)
+		assert.Equal(t, "BATCH_TOO_LARGE", *rsp.ErrorCode)
+	})
+
+	t.Run("not an array", func(t *testing.T) {
+		_, rsp := decode(`{"name":"a"}`, 0)
+		assert.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+		assert.Equal(t, "INVALID_JSON", *rsp.ErrorCode)
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		_, rsp := decode(`[{"name":"a"}] [{"name":"b"}]`, 0)
+		assert.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+		assert.Equal(t, "Invalid batch: unexpected data after the array", rsp.Message)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		items, rsp := decode(`[]`, 0)
+		assert.Nil(t, rsp)
+		assert.Equal(t, []batchItem{}, items)
+	})
+
+	t.Run("null items of a pointer type", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`[{"name":"a"},null]`))
+		_, rsp := DecodeBatch[*batchItem](req, 0)
+		assert.Equal(t, "INVALID_BATCH", *rsp.ErrorCode)
+		assert.Equal(t, []FieldError{NewFieldError("1", "required", "item 1 is null").WithPointer("/1")}, rsp.Errors)
+	})
+}
+
+func TestFromResponseBytes(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Request-ID", "req-9")
+	header.Set("X-RateLimit-Remaining", "4")
+
+	got, err := FromResponseBytes(http.StatusConflict, header, []byte(`{"success":false,"message":"dup","errorCode":"DUP"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, got.StatusCode)
+	assert.Equal(t, "req-9", got.RequestID)
+	assert.Equal(t, "4", got.Headers.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "DUP", *got.ErrorCode)
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := FromResponseBytes(http.StatusOK, nil, []byte(`nope`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxEchoBody bounds the request body echoed by EchoRequest, in bytes.
+const maxEchoBody = 2048
+
+// maxEchoParse bounds the request body EchoRequest reads to redact it, in bytes.
+const maxEchoParse = 64 << 10
+
+// sensitiveKeys are redacted by EchoRequest from query parameters and JSON and form
+// bodies when a key contains one of them, ignoring case.
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "apikey", "api_key"}
+
+// EchoRequest attaches the request that produced the response to Details under
+// `request` (method, path, query and body), to speed up reproducing client-reported
+// errors. Only when DebugMode is on; otherwise the response is unchanged.
+//
+// Sensitive query parameters and JSON or form body keys (passwords, tokens, ...) are
+// redacted, and the redacted body is truncated to 2KB. Other bodies are described
+// by their size and content type only. The body is left readable for later handlers.
+func (r *APIResponse) EchoRequest(req *http.Request) *APIResponse {
+	if !DebugMode {
+		return r
+	}
+
+	query := req.URL.Query()
+	for key := range query {
+		if isSensitiveKey(key) {
+			query[key] = []string{"[REDACTED]"}
+		}
+	}
+
+	echo := map[string]any{
+		"method": req.Method,
+		"path":   req.URL.Path,
+		"query":  query,
+	}
+	if body := echoBody(req); body != nil {
+		echo["body"] = body
+	}
+	return r.WithDetail("request", echo)
+}
+
+// echoBody reads the request body, restoring it for later readers, and returns it
+// redacted: JSON bodies as JSON and form bodies as an encoded query string, both cut
+// to maxEchoBody bytes after redaction. Bodies that can't be parsed and redacted
+// (other content types, or larger than maxEchoParse) are only described by their
+// size and content type. It returns nil when there is no body.
+func echoBody(req *http.Request) any {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	b, _ := io.ReadAll(io.LimitReader(req.Body, maxEchoParse+1))
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), req.Body), req.Body}
+
+	if len(b) == 0 {
+		return nil
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if len(b) <= maxEchoParse {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType == "application/x-www-form-urlencoded" {
+			if values, err := url.ParseQuery(string(b)); err == nil {
+				for key := range values {
+					if isSensitiveKey(key) {
+						values[key] = []string{"[REDACTED]"}
+					}
+				}
+				return truncateEcho(values.Encode())
+			}
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err == nil && !dec.More() {
+			v = redact(v)
+			redacted, _ := json.Marshal(v)
+			if len(redacted) <= maxEchoBody {
+				return v
+			}
+			return truncateEcho(string(redacted))
+		}
+	}
+
+	size := int64(len(b))
+	if size > maxEchoParse {
+		size = req.ContentLength
+	}
+	description := map[string]any{"contentType": contentType}
+	if size >= 0 {
+		description["size"] = size
+	}
+	return description
+}
+
+// truncateEcho cuts an already redacted body to maxEchoBody bytes.
+func truncateEcho(body string) string {
+	if len(body) <= maxEchoBody {
+		return body
+	}
+	return body[:maxEchoBody] + "...(truncated)"
+}
+
+// redact replaces the values of sensitive keys in a generic JSON value.
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSensitiveKey(key) {
+				v[key] = "[REDACTED]"
+				continue
+			}
+			v[key] = redact(value)
+		}
+	case []any:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+	return v
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package response
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_EchoRequest(t *testing.T) {
+	defer func(v bool) { DebugMode = v }(DebugMode)
+
+	body := `{"email":"a@b.c","password":"hunter2","profile":{"apiKey":"k"}}`
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/users?page=2&access_token=abc", strings.NewReader(body))
+	}
+
+	t.Run("off outside debug mode", func(t *testing.T) {
+		DebugMode = false
+		assert.Nil(t, BadRequest("", "").EchoRequest(newRequest()).Details)
+	})
+
+	t.Run("echoes a redacted request", func(t *testing.T) {
+		DebugMode = true
+		req := newRequest()
+
+		got := BadRequest("", "").EchoRequest(req)
+		assert.Equal(t, map[string]any{
+			"method": "POST",
+			"path":   "/users",
+			"query":  url.Values{"page": {"2"}, "access_token": {"[REDACTED]"}},
+			"body": map[string]any{
+				"email":    "a@b.c",
+				"password": "[REDACTED]",
+				"profile":  map[string]any{"apiKey": "[REDACTED]"},
+			},
+		}, got.Details["request"])
+
+		rest, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, string(rest))
+	})
+
+	t.Run("redacts large JSON bodies before truncating", func(t *testing.T) {
+		DebugMode = true
+		body := `{"padding":"` + strings.Repeat("x", 3000) + `","password":"hunter2"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		echo := BadRequest("", "").EchoRequest(req).Details["request"].(map[string]any)
+		assert.Len(t, echo["body"], maxEchoBody+len("...(truncated)"))
+		assert.NotContains(t, echo["body"], "hunter2")
+	})
+
+	t.Run("redacts form bodies", func(t *testing.T) {
+		DebugMode = true
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("user=bob&password=hunter2"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		echo := BadRequest("", "").EchoRequest(req).Details["request"].(map[string]any)
+		assert.Equal(t, "password=%5BREDACTED%5D&user=bob", echo["body"])
+	})
+
+	t.Run("describes other bodies", func(t *testing.T) {
+		DebugMode = true
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("token hunter2"))
+		req.Header.Set("Content-Type", "text/plain")
+
+		echo := BadRequest("", "").EchoRequest(req).Details["request"].(map[string]any)
+		assert.Equal(t, map[string]any{"size": int64(13), "contentType": "text/plain"}, echo["body"])
+	})
+}
@@ -0,0 +1,300 @@
+package response
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// StatusClientClosedRequest is the non-standard (nginx) status used when the
+// client canceled the request before a response was produced.
+const StatusClientClosedRequest = 499
+
+// Sentinel errors matching the kind of an error response, so callers can branch
+// with the errors package: errors.Is(err, response.ErrNotFound).
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrValidation         = errors.New("validation failed")
+	ErrInternal           = errors.New("internal server error")
+)
+
+// sentinelStatusCode returns the status code of the sentinel target. It compares
+// with == rather than indexing a map, which panics on unhashable error types.
+func sentinelStatusCode(target error) (int, bool) {
+	switch target {
+	case ErrBadRequest:
+		return http.StatusBadRequest, true
+	case ErrUnauthorized:
+		return http.StatusUnauthorized, true
+	case ErrForbidden:
+		return http.StatusForbidden, true
+	case ErrNotFound:
+		return http.StatusNotFound, true
+	case ErrConflict:
+		return http.StatusConflict, true
+	case ErrPreconditionFailed:
+		return http.StatusPreconditionFailed, true
+	case ErrValidation:
+		return http.StatusUnprocessableEntity, true
+	case ErrInternal:
+		return http.StatusInternalServerError, true
+	}
+	return 0, false
+}
+
+// Is reports whether the response matches target. A sentinel such as ErrNotFound
+// matches responses with its status code; another *APIResponse matches when both
+// the status code and error code are equal.
+func (r *APIResponse) Is(target error) bool {
+	if statusCode, ok := sentinelStatusCode(target); ok {
+		return r.StatusCode == statusCode
+	}
+
+	if t, ok := target.(*APIResponse); ok {
+		sameCode := (r.ErrorCode == nil && t.ErrorCode == nil) ||
+			(r.ErrorCode != nil && t.ErrorCode != nil && *r.ErrorCode == *t.ErrorCode)
+		return r.StatusCode == t.StatusCode && sameCode
+	}
+
+	return false
+}
+
+// StatusError adapts the response to the `(int, error)` idiom of frameworks whose
+// handlers return a status and an error. Responses with an error status (>= 400) are
+// returned as the error; any other returns a nil error, whatever Success says (e.g. a
+// 304 or a 200 GraphQLLike response).
+func (r *APIResponse) StatusError() (int, error) {
+	status := r.httpStatus()
+	if status >= http.StatusBadRequest {
+		return status, r
+	}
+	return status, nil
+}
+
+// CodeOf returns the error code of the first *APIResponse in err's chain, so callers
+// can branch on it through wrapped errors. It reports false when the chain holds no
+// response or the response has no error code. The full response is recovered with
+// errors.As:
+//
+//	var rsp *response.APIResponse
+//	if errors.As(err, &rsp) { ... }
+func CodeOf(err error) (string, bool) {
+	var rsp *APIResponse
+	if !errors.As(err, &rsp) || rsp.ErrorCode == nil {
+		return "", false
+	}
+	return *rsp.ErrorCode, true
+}
+
+// AutoError builds an error response by inspecting err's chain for well-known
+// sentinel errors:
+//
+//   - *APIResponse: returned as-is.
+//   - context.DeadlineExceeded: 504 Gateway Timeout, code TIMEOUT.
+//   - context.Canceled: 499 Client Closed Request, code CLIENT_CLOSED_REQUEST.
+//   - sql.ErrNoRows: 404 Not Found, code NOT_FOUND.
+//   - os.ErrPermission: 403 Forbidden, code FORBIDDEN.
+//
+// Anything else becomes a 500 with code INTERNAL. The error text is never used as the message, so
+// internal details don't leak to clients. A nil error returns nil.
+func AutoError(err error) *APIResponse {
+	if err == nil {
+		return nil
+	}
+
+	var rsp *APIResponse
+	switch {
+	case errors.As(err, &rsp):
+		return rsp
+	case errors.Is(err, context.DeadlineExceeded):
+		return Error(http.StatusGatewayTimeout, "Request timed out", "TIMEOUT")
+	case errors.Is(err, context.Canceled):
+		return Error(StatusClientClosedRequest, "Request was canceled", "CLIENT_CLOSED_REQUEST")
+	case errors.Is(err, sql.ErrNoRows):
+		return NotFound("", "NOT_FOUND")
+	case errors.Is(err, os.ErrPermission):
+		return Forbidden("", "FORBIDDEN")
+	default:
+		return InternalServerError("", "INTERNAL")
+	}
+}
+
+// FromError builds an error response from domain errors that describe their own
+// HTTP semantics by implementing `StatusCode() int` and/or `ErrorCode() string`
+// anywhere in err's chain. This keeps the domain layer HTTP-agnostic while letting
+// it control the response.
+//
+// When a status code is found, err.Error() becomes the message. Errors without a
+// (valid error) status code fall back to a 500 with the default message. Without an
+// error code, the status text in upper snake case (or INTERNAL for the 500 fallback)
+// is used. An *APIResponse in the chain is returned as-is, and a nil error returns nil.
+func FromError(err error) *APIResponse {
+	if err == nil {
+		return nil
+	}
+
+	var rsp *APIResponse
+	if errors.As(err, &rsp) {
+		return rsp
+	}
+
+	var errorCode string
+	var coder interface{ ErrorCode() string }
+	if errors.As(err, &coder) {
+		errorCode = coder.ErrorCode()
+	}
+
+	var statuser interface{ StatusCode() int }
+	if errors.As(err, &statuser) && statuser.StatusCode() >= http.StatusBadRequest {
+		if errorCode == "" {
+			errorCode = statusErrorCode(statuser.StatusCode())
+		}
+		return Error(statuser.StatusCode(), err.Error(), errorCode)
+	}
+
+	if errorCode == "" {
+		errorCode = "INTERNAL"
+	}
+	return InternalServerError("", errorCode)
+}
+
+// Externalize hides internal details of server errors before they reach external
+// clients. For 5xx responses it replaces Message with a generic one, keeping the
+// original message as the unexported cause (for logging) and ErrorCode as a reference.
+// Other responses are returned unchanged.
+func (r *APIResponse) Externalize() *APIResponse {
+	if r.StatusCode < http.StatusInternalServerError {
+		return r
+	}
+
+	if r.cause == nil {
+		r.cause = errors.New(r.Message)
+	}
+	r.Message = "Something went wrong on our end."
+	return r
+}
+
+// FirstError returns the first error response (4xx or 5xx) among responses,
+// skipping nil and non-error ones such as a 304 or a 200 GraphQLLike partial result,
+// or nil when there is none. Use it to pick the result of a
+// validation chain where the earliest failure wins.
+func FirstError(responses ...*APIResponse) *APIResponse {
+	for _, r := range responses {
+		if r != nil && r.httpStatus() >= http.StatusBadRequest {
+			return r
+		}
+	}
+	return nil
+}
+
+// WorstError returns the error response with the highest status code among
+// responses, so a 5xx outranks a 4xx; ties go to the earliest. Nil responses and
+// those below 400 are skipped, and nil is returned when there is no error.
+func WorstError(responses ...*APIResponse) *APIResponse {
+	var worst *APIResponse
+	for _, r := range responses {
+		if r == nil || r.httpStatus() < http.StatusBadRequest {
+			continue
+		}
+		if worst == nil || r.httpStatus() > worst.httpStatus() {
+			worst = r
+		}
+	}
+	return worst
+}
+
+// WithCause records the underlying error that led to the response. The cause is
+// never serialized, but is available to logs and tests through Cause and to the
+// errors package through Unwrap.
+func (r *APIResponse) WithCause(err error) *APIResponse {
+	r.cause = err
+	return r
+}
+
+// Cause returns the underlying error of the response, as set by WithCause or
+// Externalize, or nil.
+func (r *APIResponse) Cause() error {
+	return r.cause
+}
+
+// Unwrap returns the cause, so errors.Is and errors.As see through the response to
+// the error that led to it.
+func (r *APIResponse) Unwrap() error {
+	return r.cause
+}
+
+// LogAndReturn logs the response with logger (slog.Default when nil) and returns it
+// unchanged, for the log-then-propagate pattern on downstream errors. 5xx responses
+// are logged at error level, 4xx at warn and others at info, with the status code,
+// message, error code, Details, the unexported cause and any WithLogField fields.
+//
+//	rsp, err := response.FromHTTPResponse(resp)
+//	if err != nil {
+//		return nil, err
+//	}
+//	return nil, rsp.LogAndReturn(logger)
+func (r *APIResponse) LogAndReturn(logger *slog.Logger) *APIResponse {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	level := slog.LevelInfo
+	switch status := r.httpStatus(); {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("statusCode", r.httpStatus()),
+		slog.String("message", r.Message),
+	}
+	if r.ErrorCode != nil {
+		attrs = append(attrs, slog.String("errorCode", *r.ErrorCode))
+	}
+	if r.Details != nil {
+		attrs = append(attrs, slog.Any("details", r.Details))
+	}
+	if r.cause != nil {
+		attrs = append(attrs, slog.String("cause", r.cause.Error()))
+	}
+	attrs = append(attrs, r.logAttrs...)
+
+	logger.LogAttrs(context.Background(), level, "response", attrs...)
+	return r
+}
+
+var (
+	errorDocsMu sync.RWMutex
+	errorDocs   = map[string]string{}
+)
+
+// RegisterErrorDoc links an error code to its documentation. Responses created
+// afterwards with that code carry the URL in HelpURL, and Write emits it as a
+// `Link: <docURL>; rel="help"` header.
+func RegisterErrorDoc(code, docURL string) {
+	errorDocsMu.Lock()
+	defer errorDocsMu.Unlock()
+	errorDocs[code] = docURL
+}
+
+// errorDoc returns the documentation URL registered for code, if any.
+func errorDoc(code string) string {
+	if code == "" {
+		return ""
+	}
+
+	errorDocsMu.RLock()
+	defer errorDocsMu.RUnlock()
+	return errorDocs[code]
+}
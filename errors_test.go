@@ -0,0 +1,237 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"deadline exceeded", context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{"canceled", context.Canceled, StatusClientClosedRequest},
+		{"wrapped no rows", fmt.Errorf("find user: %w", sql.ErrNoRows), http.StatusNotFound},
+		{"permission", &os.PathError{Op: "open", Path: "/x", Err: os.ErrPermission}, http.StatusForbidden},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError},
+		{"api response in chain", fmt.Errorf("wrap: %w", Conflict("", "DUP")), http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AutoError(tt.err)
+			assert.Equal(t, tt.wantStatus, got.StatusCode)
+			assert.False(t, got.Success)
+		})
+	}
+
+	t.Run("nil error", func(t *testing.T) {
+		assert.Nil(t, AutoError(nil))
+	})
+}
+
+type domainError struct {
+	status int
+	code   string
+}
+
+func (e domainError) Error() string     { return "domain failure" }
+func (e domainError) StatusCode() int   { return e.status }
+func (e domainError) ErrorCode() string { return e.code }
+
+type codedError struct{}
+
+func (codedError) Error() string     { return "coded" }
+func (codedError) ErrorCode() string { return "CODED" }
+
+func TestFromError(t *testing.T) {
+	t.Run("implements status and code", func(t *testing.T) {
+		got := FromError(fmt.Errorf("service: %w", domainError{status: http.StatusConflict, code: "DUP_EMAIL"}))
+
+		assert.Equal(t, http.StatusConflict, got.StatusCode)
+		assert.Equal(t, "service: domain failure", got.Message)
+		assert.Equal(t, "DUP_EMAIL", *got.ErrorCode)
+	})
+
+	t.Run("non error status falls back to 500", func(t *testing.T) {
+		got := FromError(domainError{status: http.StatusOK, code: "X"})
+		assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+		assert.Equal(t, "X", *got.ErrorCode)
+	})
+
+	t.Run("only error code", func(t *testing.T) {
+		got := FromError(codedError{})
+		assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+		assert.Equal(t, "CODED", *got.ErrorCode)
+		assert.Equal(t, "Something went wrong on our end.", got.Message)
+	})
+
+	t.Run("plain error", func(t *testing.T) {
+		got := FromError(errors.New("boom"))
+		assert.Equal(t, http.StatusInternalServerError, got.StatusCode)
+		assert.Equal(t, "INTERNAL", *got.ErrorCode)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		assert.Nil(t, FromError(nil))
+	})
+}
+
+func TestAPIResponse_Is(t *testing.T) {
+	err := fmt.Errorf("load user: %w", NotFound("", "USR_404"))
+
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrConflict))
+	assert.True(t, errors.Is(err, NotFound("other message", "USR_404")))
+	assert.False(t, errors.Is(err, NotFound("", "ORD_404")))
+	assert.True(t, errors.Is(ValidationFailed("", ""), ErrValidation))
+	assert.False(t, errors.Is(err, unhashableError{}))
+}
+
+// unhashableError is an error type that can't be used as a map key.
+type unhashableError []string
+
+func (unhashableError) Error() string { return "unhashable" }
+
+func TestAPIResponse_StatusError(t *testing.T) {
+	rsp := NotFound("", "")
+	status, err := rsp.StatusError()
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Same(t, rsp, err)
+
+	status, err = Created("", nil).StatusError()
+	assert.Equal(t, http.StatusCreated, status)
+	assert.NoError(t, err)
+
+	status, err = NewAPIResponse(http.StatusNotModified, false, "", "", nil).StatusError()
+	assert.Equal(t, http.StatusNotModified, status)
+	assert.NoError(t, err)
+}
+
+func TestCodeOf(t *testing.T) {
+	err := fmt.Errorf("checkout: %w", fmt.Errorf("charge: %w", PaymentRequired("", "CARD_DECLINED")))
+
+	code, ok := CodeOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, "CARD_DECLINED", code)
+
+	var rsp *APIResponse
+	assert.True(t, errors.As(err, &rsp))
+	assert.Equal(t, http.StatusPaymentRequired, rsp.StatusCode)
+
+	_, ok = CodeOf(fmt.Errorf("wrap: %w", NotFound("", "")))
+	assert.False(t, ok)
+	_, ok = CodeOf(errors.New("plain"))
+	assert.False(t, ok)
+	_, ok = CodeOf(nil)
+	assert.False(t, ok)
+}
+
+func TestAPIResponse_Externalize(t *testing.T) {
+	t.Run("server error", func(t *testing.T) {
+		rsp := InternalServerError("pq: relation users does not exist", "DB_001").Externalize()
+
+		assert.Equal(t, "Something went wrong on our end.", rsp.Message)
+		assert.Equal(t, "DB_001", *rsp.ErrorCode)
+		assert.EqualError(t, rsp.cause, "pq: relation users does not exist")
+
+		v, err := rsp.ToJson()
+		assert.NoError(t, err)
+		assert.NotContains(t, v, "relation users")
+	})
+
+	t.Run("client error is unchanged", func(t *testing.T) {
+		rsp := BadRequest("email is invalid", "").Externalize()
+		assert.Equal(t, "email is invalid", rsp.Message)
+		assert.Nil(t, rsp.cause)
+	})
+}
+
+func TestFirstAndWorstError(t *testing.T) {
+	badRequest := BadRequest("", "")
+	notFound := NotFound("", "")
+	internal := InternalServerError("", "")
+	conflict := Conflict("", "")
+
+	assert.Same(t, badRequest, FirstError(nil, OK("", nil), badRequest, internal))
+	assert.Same(t, internal, WorstError(nil, badRequest, internal, notFound))
+	assert.Same(t, notFound, WorstError(notFound, badRequest, Error(http.StatusNotFound, "", "")))
+	assert.Same(t, conflict, WorstError(conflict, OK("", nil)))
+
+	assert.Nil(t, FirstError(nil, OK("", nil)))
+	assert.Nil(t, WorstError())
+
+	notModified := NotModified("v1")
+	partial := GraphQLLike(map[string]any{"user": nil}, []FieldError{NewFieldError("user", "not_found", "user not found")})
+	assert.Nil(t, FirstError(notModified, partial))
+	assert.Nil(t, WorstError(OK("", nil), notModified, partial))
+	assert.Same(t, notFound, FirstError(notModified, partial, notFound))
+	assert.Same(t, notFound, WorstError(notModified, notFound, partial))
+}
+
+func TestAPIResponse_WithCause(t *testing.T) {
+	cause := fmt.Errorf("query users: %w", sql.ErrConnDone)
+	rsp := InternalServerError("", "DB_001").WithCause(cause)
+
+	assert.Same(t, cause, rsp.Cause())
+	assert.ErrorIs(t, fmt.Errorf("handler: %w", rsp), sql.ErrConnDone)
+
+	v, err := rsp.ToJson()
+	assert.NoError(t, err)
+	assert.NotContains(t, v, "query users")
+
+	assert.Nil(t, OK("", nil).Cause())
+}
+
+func TestAPIResponse_LogAndReturn(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	rsp := InternalServerError("db down", "DB_001").Externalize().WithLogField("tenant", "acme")
+	assert.Same(t, rsp, rsp.LogAndReturn(logger))
+	assert.Equal(t, `level=ERROR msg=response statusCode=500 message="Something went wrong on our end." errorCode=DB_001 cause="db down" tenant=acme`+"\n", buf.String())
+
+	buf.Reset()
+	NotFound("", "").WithDetail("id", 7).LogAndReturn(logger)
+	assert.Equal(t, `level=WARN msg=response statusCode=404 message="Requested resource not found" details=map[id:7]`+"\n", buf.String())
+}
+
+func TestRegisterErrorDoc(t *testing.T) {
+	RegisterErrorDoc("CARD_DECLINED", "https://docs.test/errors/card-declined")
+
+	rsp := BadRequest("card declined", "CARD_DECLINED")
+	assert.Equal(t, "https://docs.test/errors/card-declined", rsp.HelpURL)
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, rsp.Write(rec))
+	assert.Equal(t, `<https://docs.test/errors/card-declined>; rel="help"`, rec.Header().Get("Link"))
+	assert.Contains(t, rec.Body.String(), `"helpUrl":"https://docs.test/errors/card-declined"`)
+
+	t.Run("unregistered code", func(t *testing.T) {
+		rsp := BadRequest("", "UNDOCUMENTED")
+		assert.Empty(t, rsp.HelpURL)
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, rsp.Write(rec))
+		assert.Empty(t, rec.Header().Get("Link"))
+	})
+}
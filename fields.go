@@ -0,0 +1,75 @@
+package response
+
+import "strings"
+
+// SelectFields prunes Data down to the requested fields, JSON:API sparse fieldset
+// style. Paths name top-level keys ("id") or nested ones with dots ("address.city");
+// when Data, or a value along a path, is an array the selection applies to each
+// element. A path selects its whole value even when longer paths below it are also
+// requested. Requested fields that don't exist are skipped.
+//
+// Data is replaced by its generic JSON form (maps, slices, json.Number, ...), so
+// integers beyond float64 precision such as 64-bit IDs are kept exactly. With no
+// paths, or when Data cannot be marshaled, the response is left unchanged.
+func (r *APIResponse) SelectFields(paths []string) *APIResponse {
+	if len(paths) == 0 || r.Data == nil {
+		return r
+	}
+
+	data, err := normalizeJSONExact(r.Data)
+	if err != nil {
+		return r
+	}
+
+	tree := fieldTree{}
+	for _, path := range paths {
+		node := tree
+		keys := strings.Split(path, ".")
+		for i, key := range keys {
+			children, seen := node[key]
+			if seen && children == nil {
+				break // already selected whole
+			}
+			if i == len(keys)-1 {
+				node[key] = nil
+				break
+			}
+			if !seen {
+				children = fieldTree{}
+				node[key] = children
+			}
+			node = children
+		}
+	}
+
+	r.Data = selectFields(data, tree)
+	return r
+}
+
+// fieldTree holds the selected paths; a key with nil children selects the whole value.
+type fieldTree map[string]fieldTree
+
+func selectFields(v any, tree fieldTree) any {
+	switch v := v.(type) {
+	case []any:
+		for i := range v {
+			v[i] = selectFields(v[i], tree)
+		}
+		return v
+
+	case map[string]any:
+		selected := make(map[string]any, len(tree))
+		for key, children := range tree {
+			value, ok := v[key]
+			if !ok {
+				continue
+			}
+			if children != nil {
+				value = selectFields(value, children)
+			}
+			selected[key] = value
+		}
+		return selected
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_SelectFields(t *testing.T) {
+	type address struct {
+		City    string `json:"city"`
+		Country string `json:"country"`
+	}
+	type user struct {
+		ID      int     `json:"id"`
+		Name    string  `json:"name"`
+		Email   string  `json:"email"`
+		Address address `json:"address"`
+	}
+	u := user{ID: 1, Name: "john", Email: "j@x.io", Address: address{City: "Lagos", Country: "NG"}}
+
+	t.Run("top-level and nested fields", func(t *testing.T) {
+		got := OK("", u).SelectFields([]string{"id", "address.city", "missing", "name.first"})
+		assert.Equal(t, map[string]any{
+			"id":      json.Number("1"),
+			"name":    "john",
+			"address": map[string]any{"city": "Lagos"},
+		}, got.Data)
+	})
+
+	t.Run("applied to each element of a list", func(t *testing.T) {
+		got := List("", []user{u, u}, nil).SelectFields([]string{"email"})
+		assert.Equal(t, []any{
+			map[string]any{"email": "j@x.io"},
+			map[string]any{"email": "j@x.io"},
+		}, got.Data)
+	})
+
+	t.Run("whole value wins over nested paths", func(t *testing.T) {
+		want := map[string]any{"address": map[string]any{"city": "Lagos", "country": "NG"}}
+		assert.Equal(t, want, OK("", u).SelectFields([]string{"address", "address.city"}).Data)
+		assert.Equal(t, want, OK("", u).SelectFields([]string{"address.city", "address"}).Data)
+	})
+
+	t.Run("64-bit ids kept exactly", func(t *testing.T) {
+		got := OK("", map[string]any{"id": int64(9007199254740993), "name": "john"}).SelectFields([]string{"id"})
+		assert.Equal(t, map[string]any{"id": json.Number("9007199254740993")}, got.Data)
+
+		v, err := got.ToJson()
+		assert.NoError(t, err)
+		assert.Contains(t, v, `"data":{"id":9007199254740993}`)
+	})
+
+	t.Run("no paths leaves data unchanged", func(t *testing.T) {
+		assert.Equal(t, u, OK("", u).SelectFields(nil).Data)
+	})
+}
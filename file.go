@@ -0,0 +1,113 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// FileFromPath creates a response that streams the file at path as its body. The
+// Content-Type is derived from the extension and Content-Length from the file size
+// when the response is written. The file is only opened then, and never buffered
+// whole; serve it with ServeHTTP to also support range and conditional requests.
+//
+// A missing file (or a directory) yields a NotFound response rather than an error;
+// other stat failures are returned as errors. A file that can no longer be opened
+// when the response is written yields a NotFound response with code FILE_NOT_FOUND.
+func FileFromPath(path string) (*APIResponse, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return NotFound("", "FILE_NOT_FOUND"), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	rsp := NewAPIResponse(http.StatusOK, IsSuccessStatus(http.StatusOK), "", "", nil)
+	rsp.filePath = path
+	rsp.SetHeader("Content-Type", contentType)
+	return rsp, nil
+}
+
+// ServeHTTP makes APIResponse an http.Handler. File responses are served with
+// http.ServeContent, supporting range and conditional requests; any other response
+// is sent with Write. Transformers run first in both cases.
+func (r *APIResponse) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r = r.transform()
+	if r.filePath == "" {
+		_ = r.write(w)
+		return
+	}
+	if r.alreadySent(w) {
+		return
+	}
+
+	f, info, err := r.openFile(w)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	r.writeHeaders(w)
+	// ServeContent computes the length itself, which differs for range requests.
+	w.Header().Del("Content-Length")
+	http.ServeContent(w, req, filepath.Base(r.filePath), info.ModTime(), f)
+	sealResponse(w)
+
+	if OnResponse != nil {
+		OnResponse(r)
+	}
+}
+
+// writeFile streams the response file to w.
+func (r *APIResponse) writeFile(w http.ResponseWriter) error {
+	if r.alreadySent(w) {
+		return ErrAlreadySent
+	}
+
+	f, info, err := r.openFile(w)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r.writeHeaders(w)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	w.WriteHeader(r.httpStatus())
+	_, err = io.Copy(w, f)
+	sealResponse(w)
+
+	if OnResponse != nil {
+		OnResponse(r)
+	}
+	return err
+}
+
+// openFile opens the response file. When that fails, an error response is written
+// to w in its place: NotFound with code FILE_NOT_FOUND when the file can't be opened,
+// InternalServerError when it can't be stat'ed.
+func (r *APIResponse) openFile(w http.ResponseWriter) (*os.File, fs.FileInfo, error) {
+	f, err := os.Open(r.filePath)
+	if err != nil {
+		_ = NotFound("", "FILE_NOT_FOUND").Write(w)
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		_ = InternalServerError("", "INTERNAL").Write(w)
+		return nil, nil, err
+	}
+	return f, info, nil
+}
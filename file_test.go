@@ -0,0 +1,98 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFromPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("hello world"), 0o600))
+
+	t.Run("write streams the file", func(t *testing.T) {
+		rsp, err := FileFromPath(path)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, rsp.Write(rec))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "11", rec.Header().Get("Content-Length"))
+		assert.Equal(t, "hello world", rec.Body.String())
+	})
+
+	t.Run("serve supports ranges", func(t *testing.T) {
+		rsp, err := FileFromPath(path)
+		assert.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/report.txt", nil)
+		req.Header.Set("Range", "bytes=6-10")
+		rec := httptest.NewRecorder()
+		rsp.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusPartialContent, rec.Code)
+		assert.Equal(t, "5", rec.Header().Get("Content-Length"))
+		assert.Equal(t, "world", rec.Body.String())
+	})
+
+	t.Run("size is taken at write time", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "grows.txt")
+		assert.NoError(t, os.WriteFile(path, []byte("a"), 0o600))
+		rsp, err := FileFromPath(path)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, []byte("abc"), 0o600))
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, rsp.Write(rec))
+		assert.Equal(t, "3", rec.Header().Get("Content-Length"))
+		assert.Equal(t, "abc", rec.Body.String())
+	})
+
+	t.Run("file removed before writing", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gone.txt")
+		assert.NoError(t, os.WriteFile(path, []byte("a"), 0o600))
+		rsp, err := FileFromPath(path)
+		assert.NoError(t, err)
+		assert.NoError(t, os.Remove(path))
+
+		rec := httptest.NewRecorder()
+		assert.Error(t, rsp.Write(rec))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"errorCode":"FILE_NOT_FOUND"`)
+
+		rec = httptest.NewRecorder()
+		rsp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("serve runs transformers", func(t *testing.T) {
+		defer func(v []func(*APIResponse) *APIResponse) { Transformers = v }(Transformers)
+		Transformers = []func(*APIResponse) *APIResponse{func(r *APIResponse) *APIResponse {
+			return r.SetHeader("X-Trace", "t1")
+		}}
+
+		rsp, err := FileFromPath(path)
+		assert.NoError(t, err)
+		rec := httptest.NewRecorder()
+		rsp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report.txt", nil))
+		assert.Equal(t, "t1", rec.Header().Get("X-Trace"))
+		assert.Equal(t, "hello world", rec.Body.String())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		rsp, err := FileFromPath(filepath.Join(t.TempDir(), "nope.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rsp.StatusCode)
+	})
+
+	t.Run("non file response is written as json", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		OK("", nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	})
+}
@@ -0,0 +1,31 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+)
+
+// AllStatusFixtures returns a representative response for each status code the
+// package has a constructor for, keyed by status code, so client test suites can
+// verify they handle every response shape. Each call returns fresh responses that
+// may be modified freely.
+func AllStatusFixtures() map[int]*APIResponse {
+	return map[int]*APIResponse{
+		http.StatusOK:                         OK("", map[string]any{"id": 1, "name": "John"}),
+		http.StatusCreated:                    CreatedAt("/users/1", "", map[string]any{"id": 1}),
+		http.StatusNotModified:                NotModified("v1"),
+		http.StatusBadRequest:                 BadRequest("", "INVALID_JSON"),
+		http.StatusUnauthorized:               Unauthorized("", "TOKEN_EXPIRED"),
+		http.StatusPaymentRequired:            PaymentRequired("", "QUOTA_EXCEEDED").WithUpgradeURL("https://example.com/upgrade"),
+		http.StatusForbidden:                  Forbidden("", "FORBIDDEN"),
+		http.StatusNotFound:                   NotFound("", "NOT_FOUND"),
+		http.StatusNotAcceptable:              NotAcceptable("", "UNSUPPORTED_FORMAT"),
+		http.StatusConflict:                   ConflictResource("user", "1"),
+		http.StatusPreconditionFailed:         PreconditionFailed("", "ETAG_MISMATCH"),
+		http.StatusUnprocessableEntity:        ValidationFailed("", "VALIDATION_FAILED", NewFieldError("email", "invalid_format", "must be a valid email")),
+		http.StatusUnavailableForLegalReasons: UnavailableForLegalReasons("", "GEO_BLOCKED"),
+		http.StatusInternalServerError:        InternalServerError("", "INTERNAL"),
+		http.StatusServiceUnavailable:         Health(map[string]error{"db": errors.New("connection refused")}),
+		http.StatusGatewayTimeout:             Error(http.StatusGatewayTimeout, "Request timed out", "TIMEOUT"),
+	}
+}
@@ -0,0 +1,24 @@
+package response
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllStatusFixtures(t *testing.T) {
+	fixtures := AllStatusFixtures()
+	assert.GreaterOrEqual(t, len(fixtures), 10)
+
+	for statusCode, rsp := range fixtures {
+		assert.Equal(t, statusCode, rsp.StatusCode)
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, rsp.Write(rec))
+		assert.Equal(t, statusCode, rec.Code)
+	}
+
+	fixtures[200].Message = "changed"
+	assert.NotEqual(t, "changed", AllStatusFixtures()[200].Message)
+}
@@ -0,0 +1,445 @@
+package response
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ToFormValues flattens the response into url.Values for clients consuming
+// `application/x-www-form-urlencoded`.
+//
+// The top-level fields (version, success, message, errorCode) become plain values.
+// Data and Meta are JSON-stringified rather than deeply flattened, so nested data
+// arrives as a single JSON string; values that cannot be marshaled are omitted.
+func (r *APIResponse) ToFormValues() url.Values {
+	values := url.Values{}
+
+	if r.Version != "" {
+		values.Set("version", r.Version)
+	}
+	values.Set("success", strconv.FormatBool(r.Success))
+	values.Set("message", r.Message)
+	if r.ErrorCode != nil {
+		values.Set("errorCode", *r.ErrorCode)
+	}
+
+	for key, v := range map[string]any{"data": r.Data, "meta": r.Meta} {
+		if v == nil {
+			continue
+		}
+		if b, err := json.Marshal(v); err == nil {
+			values.Set(key, string(b))
+		}
+	}
+
+	return values
+}
+
+// ErrNotTabular is returned by ToCSV when Data isn't a slice of structs or maps.
+var ErrNotTabular = errors.New("response data is not tabular: want a slice of structs or []map[string]any")
+
+// ToCSV writes Data as CSV with a header row. Data must be a slice (or array) of
+// structs, pointers to structs, or maps with string keys. Struct columns follow the
+// field order, named by their JSON keys; map columns are the sorted union of keys.
+// Nested values are JSON-encoded into their cell.
+func (r *APIResponse) ToCSV() ([]byte, error) {
+	rows := reflect.ValueOf(r.Data)
+	if rows.Kind() != reflect.Slice && rows.Kind() != reflect.Array {
+		return nil, ErrNotTabular
+	}
+
+	elemType := rows.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+
+	var header []string
+	var record func(row reflect.Value) ([]string, error)
+
+	switch {
+	case elemType.Kind() == reflect.Struct:
+		var fields []int
+		for i := 0; i < elemType.NumField(); i++ {
+			field := elemType.Field(i)
+			if name := jsonFieldName(field); field.IsExported() && name != "-" {
+				fields = append(fields, i)
+				header = append(header, name)
+			}
+		}
+		record = func(row reflect.Value) ([]string, error) {
+			cells := make([]string, len(fields))
+			if row.Kind() == reflect.Pointer { // nil row
+				return cells, nil
+			}
+			for i, f := range fields {
+				cell, err := csvCell(row.Field(f).Interface())
+				if err != nil {
+					return nil, err
+				}
+				cells[i] = cell
+			}
+			return cells, nil
+		}
+
+	case elemType.Kind() == reflect.Map && elemType.Key().Kind() == reflect.String:
+		keys := map[string]bool{}
+		for i := 0; i < rows.Len(); i++ {
+			for _, k := range rows.Index(i).MapKeys() {
+				keys[k.String()] = true
+			}
+		}
+		for key := range keys {
+			header = append(header, key)
+		}
+		slices.Sort(header)
+		record = func(row reflect.Value) ([]string, error) {
+			cells := make([]string, len(header))
+			for i, key := range header {
+				v := row.MapIndex(reflect.ValueOf(key).Convert(elemType.Key()))
+				if !v.IsValid() {
+					continue
+				}
+				cell, err := csvCell(v.Interface())
+				if err != nil {
+					return nil, err
+				}
+				cells[i] = cell
+			}
+			return cells, nil
+		}
+
+	default:
+		return nil, ErrNotTabular
+	}
+
+	var buf bytes.Buffer
+	cw := csv.NewWriter(&buf)
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		if row.Kind() == reflect.Interface {
+			row = row.Elem()
+		}
+		if row.Kind() == reflect.Pointer && elemType.Kind() == reflect.Struct && !row.IsNil() {
+			row = row.Elem()
+		}
+
+		cells, err := record(row)
+		if err != nil {
+			return nil, err
+		}
+		if err := cw.Write(cells); err != nil {
+			return nil, err
+		}
+	}
+
+	cw.Flush()
+	return buf.Bytes(), cw.Error()
+}
+
+// csvCell formats a single value for a CSV cell.
+func csvCell(v any) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case fmt.Stringer:
+		return val.String(), nil
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer:
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	return fmt.Sprint(v), nil
+}
+
+// WriteCSV sends Data to w as a CSV download named filename, with the
+// `text/csv` content type and an attachment `Content-Disposition`. Like Write, it
+// applies Transformers and calls OnResponse. Nothing is written when Data isn't
+// tabular; the error is returned instead.
+func (r *APIResponse) WriteCSV(w http.ResponseWriter, filename string) error {
+	rsp := r.clone().
+		SetHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename})).
+		transform()
+
+	b, err := rsp.ToCSV()
+	if err != nil {
+		return err
+	}
+	return rsp.send(w, "text/csv; charset=utf-8", b)
+}
+
+// xmlEnvelope is the XML representation of an APIResponse.
+type xmlEnvelope struct {
+	XMLName   xml.Name    `xml:"response"`
+	Version   string      `xml:"version,omitempty"`
+	RequestID string      `xml:"requestId,omitempty"`
+	Success   bool        `xml:"success"`
+	Message   string      `xml:"message"`
+	ErrorCode string      `xml:"errorCode,omitempty"`
+	HelpURL   string      `xml:"helpUrl,omitempty"`
+	Data      any         `xml:"data,omitempty"`
+	DataType  string      `xml:"dataType,omitempty"`
+	Meta      any         `xml:"meta,omitempty"`
+	Links     *xmlLinks   `xml:"links,omitempty"`
+	Warnings  *xmlWarns   `xml:"warnings,omitempty"`
+	Details   *xmlDetails `xml:"details,omitempty"`
+	Errors    *xmlErrors  `xml:"errors,omitempty"`
+	DryRun    bool        `xml:"dryRun,omitempty"`
+}
+
+// xmlLinks, xmlWarns and xmlDetails wrap lists so that empty ones are omitted.
+type (
+	xmlLinks struct {
+		Links []xmlLink `xml:"link"`
+	}
+	xmlWarns struct {
+		Warnings []string `xml:"warning"`
+	}
+	xmlDetails struct {
+		Details []xmlDetail `xml:"detail"`
+	}
+)
+
+// xmlLink is an entry of Links, e.g. `<link rel="next">/users?page=2</link>`.
+type xmlLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:",chardata"`
+}
+
+// xmlDetail is an entry of Details, e.g. `<detail key="resource">user</detail>`.
+type xmlDetail struct {
+	Key   string
+	Value any
+}
+
+// MarshalXML encodes the value as the element content, keyed by a `key` attribute.
+func (d xmlDetail) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "key"}, Value: d.Key})
+	return e.EncodeElement(d.Value, start)
+}
+
+// xmlErrors holds either the field errors or the GroupedErrors tree.
+type xmlErrors struct {
+	Fields []xmlFieldError `xml:"error,omitempty"`
+	Tree   *xmlErrorNode   `xml:"node,omitempty"`
+}
+
+type xmlFieldError struct {
+	Field   string `xml:"field"`
+	Code    string `xml:"code"`
+	Message string `xml:"message"`
+	Value   any    `xml:"value,omitempty"`
+	Pointer string `xml:"pointer,omitempty"`
+	Path    []any  `xml:"path>segment,omitempty"`
+}
+
+type xmlErrorNode struct {
+	Path     string         `xml:"path,attr"`
+	Message  string         `xml:"message,omitempty"`
+	Children []xmlErrorNode `xml:"node,omitempty"`
+}
+
+func newXMLErrorNode(n ErrorNode) *xmlErrorNode {
+	node := &xmlErrorNode{Path: n.Path, Message: n.Message}
+	for _, child := range n.Children {
+		node.Children = append(node.Children, *newXMLErrorNode(child))
+	}
+	return node
+}
+
+// ToXML marshals the response to XML under a `<response>` root element, with the
+// same fields as the JSON envelope. Links and Details become `<link rel="...">` and
+// `<detail key="...">` elements in key order. Data, Meta and Details values must be
+// XML-encodable (structs, slices and scalars; maps are not supported by
+// encoding/xml), otherwise the marshal error is returned.
+func (r *APIResponse) ToXML() ([]byte, error) {
+	env := xmlEnvelope{
+		Version:   r.Version,
+		RequestID: r.RequestID,
+		Success:   r.Success,
+		Message:   r.Message,
+		HelpURL:   r.HelpURL,
+		Data:      r.Data,
+		DataType:  r.DataType,
+		Meta:      r.Meta,
+		DryRun:    r.DryRun,
+	}
+	if r.ErrorCode != nil {
+		env.ErrorCode = *r.ErrorCode
+	}
+	if len(r.Warnings) > 0 {
+		env.Warnings = &xmlWarns{Warnings: r.Warnings}
+	}
+	if len(r.Links) > 0 {
+		env.Links = &xmlLinks{}
+		for rel, href := range r.Links {
+			env.Links.Links = append(env.Links.Links, xmlLink{Rel: rel, Href: href})
+		}
+		slices.SortFunc(env.Links.Links, func(a, b xmlLink) int { return cmp.Compare(a.Rel, b.Rel) })
+	}
+	if len(r.Details) > 0 {
+		env.Details = &xmlDetails{}
+		for key, value := range r.Details {
+			env.Details.Details = append(env.Details.Details, xmlDetail{Key: key, Value: value})
+		}
+		slices.SortFunc(env.Details.Details, func(a, b xmlDetail) int { return cmp.Compare(a.Key, b.Key) })
+	}
+
+	switch {
+	case r.errorTree != nil:
+		env.Errors = &xmlErrors{Tree: newXMLErrorNode(*r.errorTree)}
+	case len(r.Errors) > 0:
+		env.Errors = &xmlErrors{}
+		for _, fe := range r.Errors {
+			env.Errors.Fields = append(env.Errors.Fields, xmlFieldError(fe))
+		}
+	}
+	return xml.Marshal(env)
+}
+
+var (
+	serializersMu sync.RWMutex
+	// serializers maps a media type to the function encoding a response in it.
+	serializers = map[string]func(*APIResponse) ([]byte, error){
+		"application/json": func(r *APIResponse) ([]byte, error) { return json.Marshal(r) },
+		"application/xml":  (*APIResponse).ToXML,
+		"text/csv":         (*APIResponse).ToCSV,
+	}
+	// customJSON is set once a serializer replaces the default JSON one.
+	customJSON bool
+)
+
+// RegisterSerializer makes WriteFormat offer the response in another media type,
+// e.g. YAML or Protobuf, without modifying the package. contentType is matched
+// against the `Accept` header, and its subtype against `?format=`; registering an
+// existing media type replaces its serializer. JSON, XML and CSV are registered by
+// default. The default JSON serializer sends through Write; registering one for
+// `application/json` makes WriteFormat use it instead.
+//
+//	response.RegisterSerializer("application/yaml", func(r *response.APIResponse) ([]byte, error) {
+//		return yaml.Marshal(r)
+//	})
+func RegisterSerializer(contentType string, fn func(*APIResponse) ([]byte, error)) {
+	serializersMu.Lock()
+	defer serializersMu.Unlock()
+	serializers[contentType] = fn
+	if contentType == "application/json" {
+		customJSON = true
+	}
+}
+
+// serializer returns the serializer registered for mediaType.
+func serializer(mediaType string) (func(*APIResponse) ([]byte, error), bool) {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+	fn, ok := serializers[mediaType]
+	return fn, ok
+}
+
+// sendsThroughWrite reports whether mediaType is JSON with the default serializer,
+// which WriteFormat leaves to Write.
+func sendsThroughWrite(mediaType string) bool {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+	return mediaType == "application/json" && !customJSON
+}
+
+// WriteFormat writes the response in the format the client asked for. A `?format=`
+// query parameter (e.g. `json`, `xml`, `csv`: the media subtype) takes precedence over
+// the `Accept` header, whose q-values are honored. Clients without a preference get
+// JSON. When no supported format is acceptable a NotAcceptable response is written as
+// JSON instead. When the response can't be represented in the chosen format, e.g. an
+// error response or non-tabular Data as CSV, it is written as JSON with its own
+// status. Either way the response is sent with `Vary: Accept`, so caches keep
+// representations apart.
+func (r *APIResponse) WriteFormat(w http.ResponseWriter, req *http.Request) error {
+	r = r.clone().WithVary("Accept")
+
+	mediaType, ok := negotiateFormat(req)
+	if !ok {
+		return NotAcceptable("", "UNSUPPORTED_FORMAT").WithVary("Accept").Write(w)
+	}
+	if sendsThroughWrite(mediaType) {
+		return r.Write(w)
+	}
+
+	serialize, _ := serializer(mediaType)
+	rsp := r.transform()
+	body, err := serialize(rsp)
+	if err != nil {
+		return rsp.write(w)
+	}
+
+	return rsp.send(w, mediaType, body)
+}
+
+// negotiateFormat picks the media type of a registered serializer for req.
+func negotiateFormat(req *http.Request) (string, bool) {
+	if format := req.URL.Query().Get("format"); format != "" {
+		serializersMu.RLock()
+		defer serializersMu.RUnlock()
+		for mediaType := range serializers {
+			if _, subtype, _ := strings.Cut(mediaType, "/"); strings.EqualFold(subtype, format) {
+				return mediaType, true
+			}
+		}
+		return "", false
+	}
+
+	accept := req.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return "application/json", true
+	}
+
+	type candidate struct {
+		mediaType string
+		q         float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{mediaType, q})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return cmp.Compare(b.q, a.q) })
+
+	for _, c := range candidates {
+		if _, ok := serializer(c.mediaType); ok {
+			return c.mediaType, true
+		}
+		if c.mediaType == "*/*" || c.mediaType == "application/*" {
+			return "application/json", true
+		}
+	}
+	return "", false
+}
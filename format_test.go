@@ -0,0 +1,236 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_ToFormValues(t *testing.T) {
+	t.Run("success with nested data", func(t *testing.T) {
+		values := List("", []map[string]any{{"id": 1}}, map[string]any{"page": 1}).ToFormValues()
+
+		assert.Equal(t, "true", values.Get("success"))
+		assert.Equal(t, "Request was successful", values.Get("message"))
+		assert.Equal(t, `[{"id":1}]`, values.Get("data"))
+		assert.Equal(t, `{"page":1}`, values.Get("meta"))
+		assert.False(t, values.Has("errorCode"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		values := BadRequest("bad phone", "INVALID_PHONE").ToFormValues()
+
+		assert.Equal(t, "false", values.Get("success"))
+		assert.Equal(t, "INVALID_PHONE", values.Get("errorCode"))
+		assert.False(t, values.Has("data"))
+	})
+}
+
+func TestAPIResponse_ToCSV(t *testing.T) {
+	t.Run("slice of structs", func(t *testing.T) {
+		type row struct {
+			ID     int      `json:"id"`
+			Name   string   `json:"name"`
+			Tags   []string `json:"tags"`
+			Secret string   `json:"-"`
+		}
+		rsp := OK("", []*row{{ID: 1, Name: "Doe, John", Tags: []string{"a"}}, {ID: 2, Name: "Jane"}})
+
+		got, err := rsp.ToCSV()
+		assert.NoError(t, err)
+		assert.Equal(t, "id,name,tags\n1,\"Doe, John\",\"[\"\"a\"\"]\"\n2,Jane,null\n", string(got))
+	})
+
+	t.Run("slice of maps", func(t *testing.T) {
+		rsp := OK("", []map[string]any{{"b": 2, "a": "x"}, {"c": true}})
+
+		got, err := rsp.ToCSV()
+		assert.NoError(t, err)
+		assert.Equal(t, "a,b,c\nx,2,\n,,true\n", string(got))
+	})
+
+	t.Run("non tabular data", func(t *testing.T) {
+		_, err := OK("", map[string]any{"a": 1}).ToCSV()
+		assert.ErrorIs(t, err, ErrNotTabular)
+
+		_, err = OK("", []int{1, 2}).ToCSV()
+		assert.ErrorIs(t, err, ErrNotTabular)
+	})
+}
+
+func TestAPIResponse_WriteCSV(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := OK("", []map[string]any{{"a": 1}}).WriteCSV(rec, "report.csv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=report.csv`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "a\n1\n", rec.Body.String())
+
+	t.Run("transformers, hooks and sealing apply", func(t *testing.T) {
+		defer func(v []func(*APIResponse) *APIResponse) { Transformers = v }(Transformers)
+		defer func(fn func(*APIResponse)) { OnResponse = fn }(OnResponse)
+		Transformers = []func(*APIResponse) *APIResponse{func(r *APIResponse) *APIResponse {
+			return r.SetHeader("X-Api-Version", "2")
+		}}
+		var sent []*APIResponse
+		OnResponse = func(r *APIResponse) { sent = append(sent, r) }
+
+		rec := httptest.NewRecorder()
+		w := GuardWriter(rec)
+		assert.NoError(t, OK("", []map[string]any{{"a": 1}}).WriteCSV(w, "report.csv"))
+		assert.Equal(t, "2", rec.Header().Get("X-Api-Version"))
+		assert.Equal(t, "4", rec.Header().Get("Content-Length"))
+		assert.Len(t, sent, 1)
+
+		assert.ErrorIs(t, OK("", []map[string]any{{"a": 2}}).WriteCSV(w, "again.csv"), ErrAlreadySent)
+		assert.Equal(t, "a\n1\n", rec.Body.String())
+	})
+}
+
+func TestAPIResponse_ToXML(t *testing.T) {
+	type user struct {
+		ID   int    `xml:"id"`
+		Name string `xml:"name"`
+	}
+
+	got, err := OK("fetched", user{ID: 1, Name: "john"}).ToXML()
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`<response><version>1</version><success>true</success><message>fetched</message>`+
+			`<data><id>1</id><name>john</name></data></response>`,
+		string(got),
+	)
+
+	t.Run("all envelope fields", func(t *testing.T) {
+		rsp := ValidationFailed("", "VALIDATION_FAILED",
+			NewFieldError("email", "required", "is required").WithPath("user", 1))
+		rsp.RequestID = "req-1"
+		rsp.DataType = "user"
+		rsp.DryRun = true
+		rsp.Links = map[string]string{"self": "/users", "help": "/docs"}
+		rsp.Warnings = []string{"deprecated"}
+		rsp.Details = map[string]any{"resource": "user", "attempts": 2}
+
+		got, err := rsp.ToXML()
+		assert.NoError(t, err)
+		assert.Equal(t,
+			`<response><version>1</version><requestId>req-1</requestId><success>false</success>`+
+				`<message>Request failed validation</message><errorCode>VALIDATION_FAILED</errorCode>`+
+				`<dataType>user</dataType><links><link rel="help">/docs</link><link rel="self">/users</link></links>`+
+				`<warnings><warning>deprecated</warning></warnings>`+
+				`<details><detail key="attempts">2</detail><detail key="resource">user</detail></details>`+
+				`<errors><error><field>email</field><code>required</code><message>is required</message>`+
+				`<path><segment>user</segment><segment>1</segment></path></error></errors>`+
+				`<dryRun>true</dryRun></response>`,
+			string(got),
+		)
+	})
+
+	t.Run("grouped errors", func(t *testing.T) {
+		got, err := GroupedErrors(ErrorNode{Path: "orders", Children: []ErrorNode{{Path: "orders[0]", Message: "duplicate"}}}).ToXML()
+		assert.NoError(t, err)
+		assert.Contains(t, string(got),
+			`<errors><node path="orders"><node path="orders[0]"><message>duplicate</message></node></node></errors>`)
+	})
+
+	t.Run("maps are not supported", func(t *testing.T) {
+		_, err := OK("", map[string]any{"a": 1}).ToXML()
+		assert.Error(t, err)
+	})
+}
+
+func TestAPIResponse_WriteFormat(t *testing.T) {
+	rows := []map[string]any{{"id": 1}}
+
+	tests := []struct {
+		name        string
+		target      string
+		accept      string
+		wantStatus  int
+		wantType    string
+		wantBodyHas string
+	}{
+		{"no preference", "/", "", http.StatusOK, "application/json", `"data":[{"id":1}]`},
+		{"wildcard", "/", "*/*", http.StatusOK, "application/json", `"success":true`},
+		{"q-values", "/", "application/json;q=0.5, text/csv", http.StatusOK, "text/csv", "id\n1\n"},
+		{"format overrides accept", "/?format=json", "text/csv", http.StatusOK, "application/json", `"success":true`},
+		{"unsupported format", "/?format=yaml", "", http.StatusNotAcceptable, "application/json", `"success":false`},
+		{"unsupported accept", "/", "image/png", http.StatusNotAcceptable, "application/json", `UNSUPPORTED_FORMAT`},
+		{"data not representable", "/", "application/xml", http.StatusOK, "application/json", `"data":[{"id":1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			rec := httptest.NewRecorder()
+			assert.NoError(t, OK("", rows).WriteFormat(rec, req))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantType, rec.Header().Get("Content-Type"))
+			assert.Contains(t, rec.Body.String(), tt.wantBodyHas)
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+		})
+	}
+
+	t.Run("error response keeps its status", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?format=csv", nil)
+		rec := httptest.NewRecorder()
+		assert.NoError(t, NotFound("user not found", "USER_NOT_FOUND").WriteFormat(rec, req))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), `"errorCode":"USER_NOT_FOUND"`)
+		assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+	})
+}
+
+func TestRegisterSerializer(t *testing.T) {
+	RegisterSerializer("text/plain", func(r *APIResponse) ([]byte, error) { return []byte(r.Message), nil })
+	defer func() {
+		serializersMu.Lock()
+		delete(serializers, "text/plain")
+		serializersMu.Unlock()
+	}()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/?format=plain", nil),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+	} {
+		req.Header.Set("Accept", "text/plain")
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, OK("hello", nil).WriteFormat(rec, req))
+		assert.Equal(t, "text/plain", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "hello", rec.Body.String())
+	}
+}
+
+func TestRegisterSerializer_JSON(t *testing.T) {
+	serializersMu.RLock()
+	original := serializers["application/json"]
+	serializersMu.RUnlock()
+	defer func() {
+		serializersMu.Lock()
+		serializers["application/json"] = original
+		customJSON = false
+		serializersMu.Unlock()
+	}()
+
+	RegisterSerializer("application/json", func(r *APIResponse) ([]byte, error) {
+		return json.Marshal(map[string]any{"ok": r.Success, "msg": r.Message})
+	})
+
+	for _, target := range []string{"/", "/?format=json"} {
+		rec := httptest.NewRecorder()
+		assert.NoError(t, OK("hello", nil).WriteFormat(rec, httptest.NewRequest(http.MethodGet, target, nil)))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"ok":true,"msg":"hello"}`, rec.Body.String())
+	}
+}
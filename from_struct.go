@@ -0,0 +1,63 @@
+package response
+
+import "reflect"
+
+// FromStruct assembles a response from a single annotated struct, so handlers can
+// return one value instead of building the envelope by hand. Fields tagged
+// `response:"data"` go to Data and fields tagged `response:"meta"` to Meta:
+//
+//	type userPage struct {
+//		Users []User     `response:"data"`
+//		Page  Pagination `response:"meta"`
+//	}
+//
+// A single tagged field becomes the value itself; several become an object keyed by
+// their JSON names. A struct (or pointer to one) without tagged fields, or any other
+// value, is used as Data whole. The envelope is built by ForStatus, so statusCode may
+// be any code and default messages apply.
+func FromStruct(statusCode int, v any) *APIResponse {
+	data, meta := v, any(nil)
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		dataFields, metaFields := taggedFields(rv, "data"), taggedFields(rv, "meta")
+		if len(dataFields) > 0 || len(metaFields) > 0 {
+			data, meta = collapseFields(dataFields), collapseFields(metaFields)
+		}
+	}
+
+	rsp := ForStatus(statusCode, "", "", data)
+	rsp.Meta = meta
+	return rsp
+}
+
+// taggedFields returns the exported fields of struct v tagged `response:"<tag>"`,
+// keyed by their JSON names.
+func taggedFields(v reflect.Value, tag string) map[string]any {
+	fields := make(map[string]any)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() && field.Tag.Get("response") == tag {
+			fields[jsonFieldName(field)] = v.Field(i).Interface()
+		}
+	}
+	return fields
+}
+
+// collapseFields returns the only value of fields, fields itself when there are
+// several, or nil when there are none.
+func collapseFields(fields map[string]any) any {
+	switch len(fields) {
+	case 0:
+		return nil
+	case 1:
+		for _, value := range fields {
+			return value
+		}
+	}
+	return fields
+}
@@ -0,0 +1,40 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromStruct(t *testing.T) {
+	type user struct {
+		ID int `json:"id"`
+	}
+
+	t.Run("data and meta fields", func(t *testing.T) {
+		got := FromStruct(http.StatusOK, &struct {
+			Users []user   `response:"data"`
+			Page  int      `json:"page" response:"meta"`
+			Total int      `json:"total" response:"meta"`
+			Other struct{} // untagged fields are ignored
+		}{Users: []user{{ID: 1}}, Page: 2, Total: 30})
+
+		assert.Equal(t, []user{{ID: 1}}, got.Data)
+		assert.Equal(t, map[string]any{"page": 2, "total": 30}, got.Meta)
+		assert.True(t, got.Success)
+	})
+
+	t.Run("untagged struct is the data", func(t *testing.T) {
+		got := FromStruct(http.StatusCreated, user{ID: 1})
+		assert.Equal(t, user{ID: 1}, got.Data)
+		assert.Nil(t, got.Meta)
+		assert.Equal(t, http.StatusCreated, got.StatusCode)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		got := FromStruct(http.StatusNotFound, nil)
+		assert.Equal(t, "Requested resource not found", got.Message)
+		assert.False(t, got.Success)
+	})
+}
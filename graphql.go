@@ -0,0 +1,21 @@
+package response
+
+import "net/http"
+
+// GraphQLLike creates a response following the GraphQL contract where data and
+// errors coexist: the status is always (HTTP 200), Data holds whatever resolved and
+// the errors go to Errors, each locating its failed field in Data with WithPath.
+// Success is true only when errs is empty.
+//
+//	return GraphQLLike(data, []FieldError{
+//		NewFieldError("avatar", "UPSTREAM_DOWN", "avatar service unavailable").WithPath("user", "avatar"),
+//	})
+func GraphQLLike(data any, errs []FieldError) *APIResponse {
+	if len(errs) == 0 {
+		return OK("", data)
+	}
+
+	rsp := NewAPIResponse(http.StatusOK, false, "Request completed with errors", "", data)
+	rsp.Errors = errs
+	return rsp
+}
@@ -0,0 +1,34 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQLLike(t *testing.T) {
+	t.Run("partial errors", func(t *testing.T) {
+		got := GraphQLLike(map[string]any{"user": map[string]any{"name": "john", "avatar": nil}}, []FieldError{
+			NewFieldError("avatar", "UPSTREAM_DOWN", "avatar service unavailable").WithPath("user", "avatar"),
+		})
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+		assert.False(t, got.Success)
+
+		v, err := got.ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"version": "1",
+			"success": false,
+			"message": "Request completed with errors",
+			"data": {"user": {"name": "john", "avatar": null}},
+			"errors": [{"field": "avatar", "code": "UPSTREAM_DOWN", "message": "avatar service unavailable", "path": ["user", "avatar"]}]
+		}`, v)
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		got := GraphQLLike("data", nil)
+		assert.True(t, got.Success)
+		assert.Nil(t, got.Errors)
+	})
+}
@@ -0,0 +1,123 @@
+package response
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// ErrAlreadySent is returned by Write when the response is written to a
+// GuardWriter that has already sent one.
+var ErrAlreadySent = errors.New("response: a response was already sent")
+
+// OnceWriter is an http.ResponseWriter that lets only one response through, to
+// defend against handlers that accidentally write twice. A second WriteHeader is
+// ignored and logged instead of producing a "superfluous WriteHeader" warning, and
+// Write sends nothing to it once a status was sent, returning ErrAlreadySent. Body
+// writes made after a complete response (from Write, WriteJSON or ServeHTTP) are
+// dropped as well, so they can't corrupt it. Create it with GuardWriter.
+type OnceWriter struct {
+	http.ResponseWriter
+
+	mu         sync.Mutex
+	statusCode int
+	sealed     bool
+}
+
+// GuardWriter wraps w so that only the first response written to it is sent.
+func GuardWriter(w http.ResponseWriter) *OnceWriter {
+	if ow, ok := w.(*OnceWriter); ok {
+		return ow
+	}
+	return &OnceWriter{ResponseWriter: w}
+}
+
+// WriteHeader sends the status code, unless one was already sent.
+func (w *OnceWriter) WriteHeader(statusCode int) {
+	w.mu.Lock()
+	sent := w.statusCode
+	if sent == 0 {
+		w.statusCode = statusCode
+	}
+	w.mu.Unlock()
+
+	if sent != 0 {
+		slog.Warn("response error: ignoring a second response status",
+			"statusCode", statusCode, "sentStatusCode", sent)
+		return
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write sends p as part of the body, sending a 200 status first if none was sent.
+// Once a complete response was sent, p is dropped and ErrAlreadySent returned.
+func (w *OnceWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	sealed, first := w.sealed, w.statusCode == 0
+	if first && !sealed {
+		w.statusCode = http.StatusOK
+	}
+	w.mu.Unlock()
+
+	if sealed {
+		slog.Warn("response error: ignoring a body write after the response was sent", "bytes", len(p))
+		return 0, ErrAlreadySent
+	}
+	if first {
+		w.ResponseWriter.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// StatusCode returns the status code sent, or 0 if none was sent yet.
+func (w *OnceWriter) StatusCode() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.statusCode
+}
+
+// Flush flushes the underlying writer when it supports it.
+func (w *OnceWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *OnceWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// alreadySent reports, and logs, whether w is a GuardWriter that has already sent
+// a response, in which case r must not be written.
+func (r *APIResponse) alreadySent(w http.ResponseWriter) bool {
+	return alreadySent(w, r.httpStatus())
+}
+
+// alreadySent reports, and logs, whether w is a GuardWriter that has already sent
+// a response, in which case a response with statusCode must not be written.
+func alreadySent(w http.ResponseWriter, statusCode int) bool {
+	ow, ok := w.(*OnceWriter)
+	if !ok {
+		return false
+	}
+
+	sent := ow.StatusCode()
+	if sent == 0 {
+		return false
+	}
+	slog.Warn("response error: ignoring a second response",
+		"statusCode", statusCode, "sentStatusCode", sent)
+	return true
+}
+
+// sealResponse marks the response written to w as complete when w is a GuardWriter,
+// so later body writes are dropped.
+func sealResponse(w http.ResponseWriter) {
+	if ow, ok := w.(*OnceWriter); ok {
+		ow.mu.Lock()
+		ow.sealed = true
+		ow.mu.Unlock()
+	}
+}
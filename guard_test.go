@@ -0,0 +1,97 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardWriter(t *testing.T) {
+	t.Run("second response is ignored", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := GuardWriter(rec)
+
+		assert.NoError(t, Created("", map[string]int{"id": 1}).Write(w))
+		body := rec.Body.String()
+
+		assert.ErrorIs(t, InternalServerError("", "").Write(w), ErrAlreadySent)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, http.StatusCreated, w.StatusCode())
+		assert.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("second status is ignored", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := GuardWriter(rec)
+
+		_, err := w.Write([]byte("hello"))
+		assert.NoError(t, err)
+		w.WriteHeader(http.StatusTeapot)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, http.StatusOK, w.StatusCode())
+	})
+
+	t.Run("body writes after a response are dropped", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := GuardWriter(rec)
+
+		assert.NoError(t, OK("", nil).Write(w))
+		body := rec.Body.String()
+
+		_, err := w.Write([]byte("garbage"))
+		assert.ErrorIs(t, err, ErrAlreadySent)
+		assert.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("WriteJSON respects the guard", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := GuardWriter(rec)
+
+		assert.NoError(t, WriteJSON(w, http.StatusOK, map[string]bool{"ok": true}))
+		assert.ErrorIs(t, WriteJSON(w, http.StatusInternalServerError, nil), ErrAlreadySent)
+		assert.ErrorIs(t, NotFound("", "NOT_FOUND").Write(w), ErrAlreadySent)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"ok":true}`, rec.Body.String())
+	})
+
+	t.Run("file responses respect the guard", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "report.txt")
+		assert.NoError(t, os.WriteFile(path, []byte("report"), 0o600))
+		rsp, err := FileFromPath(path)
+		assert.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		w := GuardWriter(rec)
+		assert.NoError(t, OK("", nil).Write(w))
+		body := rec.Body.String()
+
+		rsp.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, body, rec.Body.String())
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		w := GuardWriter(httptest.NewRecorder())
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = w.StatusCode()
+				w.WriteHeader(http.StatusAccepted)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, http.StatusAccepted, w.StatusCode())
+	})
+
+	t.Run("wrapping twice returns the same guard", func(t *testing.T) {
+		w := GuardWriter(httptest.NewRecorder())
+		assert.Same(t, w, GuardWriter(w))
+	})
+}
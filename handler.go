@@ -0,0 +1,35 @@
+package response
+
+import "net/http"
+
+// ErrorMapper converts the errors returned to WrapHandler into error responses.
+// It defaults to AutoError; replace it, e.g. with FromError, to map domain errors.
+var ErrorMapper = AutoError
+
+// WrapHandler adapts a function returning data and an error into an
+// http.HandlerFunc, so handlers can be written as pure `(request) -> (data, error)`
+// functions. A nil error writes OK("", data), or data itself when it is an
+// *APIResponse; a non-nil error is converted by ErrorMapper and written instead.
+//
+//	mux.Handle("GET /users/{id}", response.WrapHandler(func(req *http.Request) (any, error) {
+//		return users.Get(req.Context(), req.PathValue("id"))
+//	}))
+func WrapHandler(fn func(*http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		data, err := fn(req)
+		if err != nil {
+			rsp := ErrorMapper(err)
+			if rsp == nil {
+				rsp = InternalServerError("", "INTERNAL")
+			}
+			rsp.ServeHTTP(w, req)
+			return
+		}
+
+		if rsp, ok := data.(*APIResponse); ok && rsp != nil {
+			rsp.ServeHTTP(w, req)
+			return
+		}
+		OK("", data).ServeHTTP(w, req)
+	}
+}
@@ -0,0 +1,43 @@
+package response
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapHandler(t *testing.T) {
+	serve := func(fn func(*http.Request) (any, error)) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		WrapHandler(fn)(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+		return rec
+	}
+
+	t.Run("data", func(t *testing.T) {
+		rec := serve(func(*http.Request) (any, error) { return map[string]int{"id": 1}, nil })
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful","data":{"id":1}}`, rec.Body.String())
+	})
+
+	t.Run("response", func(t *testing.T) {
+		rec := serve(func(*http.Request) (any, error) { return Created("", nil), nil })
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rec := serve(func(*http.Request) (any, error) { return nil, sql.ErrNoRows })
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("custom error mapper", func(t *testing.T) {
+		defer func(v func(error) *APIResponse) { ErrorMapper = v }(ErrorMapper)
+		ErrorMapper = func(error) *APIResponse { return nil }
+
+		rec := serve(func(*http.Request) (any, error) { return nil, errors.New("boom") })
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
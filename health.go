@@ -0,0 +1,36 @@
+package response
+
+import "net/http"
+
+// HealthStatus is the Data of a Health response.
+//
+// Status: "ok" when every check passed, "degraded" otherwise.
+// Checks: Outcome of each named check, "ok" or the error message.
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Health builds a response for health/readiness endpoints from the result of each
+// named check. It returns 200 when all checks pass (nil error) and 503 when any fails.
+//
+// return Health(map[string]error{"db": db.Ping(), "cache": cache.Ping()})
+func Health(checks map[string]error) *APIResponse {
+	status := HealthStatus{Status: "ok", Checks: make(map[string]string, len(checks))}
+
+	for name, err := range checks {
+		if err != nil {
+			status.Status = "degraded"
+			status.Checks[name] = err.Error()
+			continue
+		}
+		status.Checks[name] = "ok"
+	}
+
+	if status.Status != "ok" {
+		rsp := Error(http.StatusServiceUnavailable, "Service is degraded", "SERVICE_DEGRADED")
+		rsp.Data = status
+		return rsp
+	}
+	return OK("Service is healthy", status)
+}
@@ -0,0 +1,27 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	t.Run("all checks pass", func(t *testing.T) {
+		got := Health(map[string]error{"db": nil, "cache": nil})
+
+		assert.Equal(t, http.StatusOK, got.StatusCode)
+		assert.True(t, got.Success)
+		assert.Equal(t, HealthStatus{Status: "ok", Checks: map[string]string{"db": "ok", "cache": "ok"}}, got.Data)
+	})
+
+	t.Run("a check fails", func(t *testing.T) {
+		got := Health(map[string]error{"db": errors.New("connection refused"), "cache": nil})
+
+		assert.Equal(t, http.StatusServiceUnavailable, got.StatusCode)
+		assert.False(t, got.Success)
+		assert.Equal(t, HealthStatus{Status: "degraded", Checks: map[string]string{"db": "connection refused", "cache": "ok"}}, got.Data)
+	})
+}
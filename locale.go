@@ -0,0 +1,67 @@
+package response
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[string]map[string]string{}
+)
+
+// RegisterMessages adds translations for lang (e.g. "fr" or "pt-BR") to the message
+// catalog. messages maps a message as written in code, including the constructors'
+// default messages, to its translation.
+//
+//	response.RegisterMessages("fr", map[string]string{
+//		"Requested resource not found": "Ressource introuvable",
+//	})
+func RegisterMessages(lang string, messages map[string]string) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	lang = strings.ToLower(lang)
+	if catalog[lang] == nil {
+		catalog[lang] = make(map[string]string, len(messages))
+	}
+	for msg, translation := range messages {
+		catalog[lang][msg] = translation
+	}
+}
+
+// WithLocale resolves Message in the given language through the registered catalog,
+// falling back from a regional tag to its base language ("pt-BR" to "pt"). It sets
+// the `Content-Language` header when a translation is found and leaves Message
+// unchanged otherwise. Calling it again re-resolves the original message.
+func (r *APIResponse) WithLocale(lang string) *APIResponse {
+	if r.messageKey == "" {
+		r.messageKey = r.Message
+	}
+
+	translation, ok := lookupMessage(lang, r.messageKey)
+	if !ok {
+		return r
+	}
+
+	r.Message = translation
+	return r.SetHeader("Content-Language", lang)
+}
+
+func lookupMessage(lang, msg string) (string, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	lang = strings.ToLower(lang)
+	for {
+		if translation, ok := catalog[lang][msg]; ok {
+			return translation, true
+		}
+
+		i := strings.LastIndex(lang, "-")
+		if i < 0 {
+			return "", false
+		}
+		lang = lang[:i]
+	}
+}
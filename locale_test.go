@@ -0,0 +1,31 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_WithLocale(t *testing.T) {
+	RegisterMessages("fr", map[string]string{"Requested resource not found": "Ressource introuvable"})
+	RegisterMessages("pt", map[string]string{"Requested resource not found": "Recurso não encontrado"})
+
+	t.Run("translates default messages", func(t *testing.T) {
+		rsp := NotFound("", "").WithLocale("fr")
+		assert.Equal(t, "Ressource introuvable", rsp.Message)
+		assert.Equal(t, "fr", rsp.Headers.Get("Content-Language"))
+	})
+
+	t.Run("falls back to the base language", func(t *testing.T) {
+		assert.Equal(t, "Recurso não encontrado", NotFound("", "").WithLocale("pt-BR").Message)
+	})
+
+	t.Run("re-resolves the original message", func(t *testing.T) {
+		assert.Equal(t, "Recurso não encontrado", NotFound("", "").WithLocale("fr").WithLocale("pt").Message)
+	})
+
+	t.Run("unknown language or message", func(t *testing.T) {
+		assert.Equal(t, "Requested resource not found", NotFound("", "").WithLocale("de").Message)
+		assert.Equal(t, "custom", NotFound("custom", "").WithLocale("fr").Message)
+	})
+}
@@ -0,0 +1,234 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// EnvelopeFieldNames configures the JSON keys used for the envelope fields. An empty
+// name keeps the default key, so the zero value produces today's envelope.
+type EnvelopeFieldNames struct {
+	Version   string // default "version"
+	RequestID string // default "requestId"
+	Success   string // default "success"
+	Message   string // default "message"
+	ErrorCode string // default "errorCode"
+	HelpURL   string // default "helpUrl"
+	Data      string // default "data"
+	DataType  string // default "dataType"
+	Meta      string // default "meta"
+	Links     string // default "links"
+	Warnings  string // default "warnings"
+	Details   string // default "details"
+	Errors    string // default "errors"
+	DryRun    string // default "dryRun"
+}
+
+// FieldNames holds the JSON keys honored by MarshalJSON and UnmarshalJSON, e.g. to
+// serve a partner expecting `status` instead of `success`:
+//
+//	response.FieldNames = response.EnvelopeFieldNames{Success: "status", Message: "msg"}
+var FieldNames EnvelopeFieldNames
+
+// OmitSuccessOnTrue drops the `success` key from successful responses, for lean
+// payloads where clients infer success from the HTTP status and presence of `data`.
+// Error responses keep `"success": false`.
+var OmitSuccessOnTrue bool
+
+// AlwaysEmitErrorCode makes every response include the `errorCode` key, encoded as
+// `"errorCode": null` when no code is set, for clients preferring a fixed envelope
+// shape. By default the key is omitted when empty.
+var AlwaysEmitErrorCode bool
+
+// renames maps each default key to its configured replacement, skipping unchanged keys.
+func (n EnvelopeFieldNames) renames() map[string]string {
+	renames := make(map[string]string)
+	for key, name := range map[string]string{
+		"version":   n.Version,
+		"requestId": n.RequestID,
+		"success":   n.Success,
+		"message":   n.Message,
+		"errorCode": n.ErrorCode,
+		"helpUrl":   n.HelpURL,
+		"data":      n.Data,
+		"dataType":  n.DataType,
+		"meta":      n.Meta,
+		"links":     n.Links,
+		"warnings":  n.Warnings,
+		"details":   n.Details,
+		"errors":    n.Errors,
+		"dryRun":    n.DryRun,
+	} {
+		if name != "" && name != key {
+			renames[key] = name
+		}
+	}
+	return renames
+}
+
+// envelope has the fields of APIResponse without its methods, so it can be
+// marshaled with the default encoding/json behavior.
+type envelope APIResponse
+
+// plainJSON reports whether the response encodes exactly as its struct tags
+// describe, with no envelope customization to apply.
+func (r *APIResponse) plainJSON() bool {
+	return FieldNames == (EnvelopeFieldNames{}) &&
+		!(r.requireData && r.Data == nil) &&
+		!(OmitSuccessOnTrue && r.Success) &&
+		!(AlwaysEmitErrorCode && r.ErrorCode == nil) &&
+		r.errorTree == nil &&
+		TimeFormat == TimeRFC3339
+}
+
+// MarshalJSON encodes the response using the keys configured in FieldNames,
+// emitting `"data": null` for nil Data when RequireData was set, omitting
+// `success` when OmitSuccessOnTrue applies and emitting `"errorCode": null` when
+// AlwaysEmitErrorCode is set. Times in Data, Meta and Details follow TimeFormat, and
+// the tree of a GroupedErrors response is emitted under `errors`.
+func (r *APIResponse) MarshalJSON() ([]byte, error) {
+	e := (*envelope)(r)
+	if TimeFormat != TimeRFC3339 {
+		c := *e
+		var err error
+		if c.Data, err = formatTimes(reflect.ValueOf(r.Data)); err != nil {
+			return nil, err
+		}
+		if c.Meta, err = formatTimes(reflect.ValueOf(r.Meta)); err != nil {
+			return nil, err
+		}
+		if r.Details != nil {
+			details, err := formatTimes(reflect.ValueOf(r.Details))
+			if err != nil {
+				return nil, err
+			}
+			c.Details = details.(map[string]any)
+		}
+		e = &c
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.plainJSON() {
+		return b, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+
+	if _, ok := fields["data"]; !ok && r.requireData {
+		fields["data"] = json.RawMessage("null")
+	}
+	if OmitSuccessOnTrue && r.Success {
+		delete(fields, "success")
+	}
+	if _, ok := fields["errorCode"]; !ok && AlwaysEmitErrorCode {
+		fields["errorCode"] = json.RawMessage("null")
+	}
+	if r.errorTree != nil {
+		tree, err := json.Marshal([]ErrorNode{*r.errorTree})
+		if err != nil {
+			return nil, err
+		}
+		fields["errors"] = tree
+	}
+
+	return json.Marshal(renameKeys(fields, FieldNames.renames()))
+}
+
+// UnmarshalJSON decodes a response using the keys configured in FieldNames. An
+// error tree written by GroupedErrors is decoded back into the tree, so the
+// response encodes the same way again.
+func (r *APIResponse) UnmarshalJSON(b []byte) error {
+	return r.unmarshalJSON(b, false)
+}
+
+// unmarshalJSON is UnmarshalJSON, decoding numbers in Data, Meta, Details and field
+// error values as json.Number when exact is set.
+func (r *APIResponse) unmarshalJSON(b []byte, exact bool) error {
+	if FieldNames == (EnvelopeFieldNames{}) {
+		return r.decodeEnvelope(b, exact)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	reverse := make(map[string]string)
+	for key, name := range FieldNames.renames() {
+		reverse[name] = key
+	}
+
+	b, err := json.Marshal(renameKeys(fields, reverse))
+	if err != nil {
+		return err
+	}
+	return r.decodeEnvelope(b, exact)
+}
+
+// decodeEnvelope decodes b, with default keys, into r, see unmarshalJSON. The
+// `errors` key holds either field errors or, for GroupedErrors, a one-element array
+// with the root of an error tree, told apart by the tree's string `path`.
+func (r *APIResponse) decodeEnvelope(b []byte, exact bool) error {
+	aux := struct {
+		*envelope
+		Errors json.RawMessage `json:"errors,omitempty"`
+	}{envelope: (*envelope)(r)}
+	if err := decodeJSON(b, &aux, exact); err != nil {
+		return err
+	}
+	if len(aux.Errors) == 0 || string(aux.Errors) == "null" {
+		return nil
+	}
+
+	var paths []struct {
+		Path json.RawMessage `json:"path"`
+	}
+	if err := json.Unmarshal(aux.Errors, &paths); err == nil &&
+		len(paths) == 1 && len(paths[0].Path) > 0 && paths[0].Path[0] == '"' {
+		var tree []ErrorNode
+		if err := json.Unmarshal(aux.Errors, &tree); err != nil {
+			return err
+		}
+		r.errorTree = &tree[0]
+		return nil
+	}
+	return decodeJSON(aux.Errors, &r.Errors, exact)
+}
+
+// decodeJSON unmarshals b into v, keeping numbers as json.Number when exact is set.
+func decodeJSON(b []byte, v any, exact bool) error {
+	if !exact {
+		return json.Unmarshal(b, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("response: unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// renameKeys returns a copy of fields with keys replaced according to renames.
+func renameKeys(fields map[string]json.RawMessage, renames map[string]string) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage, len(fields))
+	for key, value := range fields {
+		if name, ok := renames[key]; ok {
+			key = name
+		}
+		out[key] = value
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldNames(t *testing.T) {
+	defer func(v EnvelopeFieldNames) { FieldNames = v }(FieldNames)
+
+	t.Run("default names", func(t *testing.T) {
+		FieldNames = EnvelopeFieldNames{}
+		v, err := NotFound("missing", "NF").ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"version":"1","success":false,"message":"missing","errorCode":"NF"}`, v)
+	})
+
+	t.Run("custom names round trip", func(t *testing.T) {
+		FieldNames = EnvelopeFieldNames{Success: "status", Message: "msg"}
+
+		v, err := OK("done", []int{1}).ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"version":"1","status":true,"msg":"done","data":[1]}`, v)
+
+		got, err := FromJsonToAPIResponse([]byte(v))
+		assert.NoError(t, err)
+		assert.True(t, got.Success)
+		assert.Equal(t, "done", got.Message)
+	})
+
+	t.Run("swapped names", func(t *testing.T) {
+		FieldNames = EnvelopeFieldNames{Data: "meta", Meta: "data"}
+
+		v, err := List("", "rows", "page").ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful","data":"page","meta":"rows"}`, v)
+	})
+}
+
+func TestAPIResponse_RequireData(t *testing.T) {
+	t.Run("omitted by default", func(t *testing.T) {
+		v, err := OK("done", nil).ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"done"}`, v)
+	})
+
+	t.Run("null when required", func(t *testing.T) {
+		v, err := OK("done", nil).RequireData().ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"done","data":null}`, v)
+
+		b, err := OK("done", nil).RequireData().AppendJSON(nil)
+		assert.NoError(t, err)
+		assert.JSONEq(t, v, string(b))
+	})
+
+	t.Run("non-nil data is unaffected", func(t *testing.T) {
+		v, err := OK("done", []int{1}).RequireData().ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"done","data":[1]}`, v)
+	})
+}
+
+func TestOmitSuccessOnTrue(t *testing.T) {
+	defer func(v bool) { OmitSuccessOnTrue = v }(OmitSuccessOnTrue)
+	OmitSuccessOnTrue = true
+
+	v, err := OK("done", []int{1}).ToJson()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","message":"done","data":[1]}`, v)
+
+	v, err = NotFound("missing", "").ToJson()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","success":false,"message":"missing"}`, v)
+}
+
+func TestAlwaysEmitErrorCode(t *testing.T) {
+	defer func(v bool) { AlwaysEmitErrorCode = v }(AlwaysEmitErrorCode)
+	AlwaysEmitErrorCode = true
+
+	v, err := OK("done", nil).ToJson()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","success":true,"message":"done","errorCode":null}`, v)
+
+	v, err = BadRequest("bad", "BAD").ToJson()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","success":false,"message":"bad","errorCode":"BAD"}`, v)
+
+	got, err := FromJsonToAPIResponse([]byte(`{"success":true,"message":"done","errorCode":null}`))
+	assert.NoError(t, err)
+	assert.Nil(t, got.ErrorCode)
+}
@@ -0,0 +1,229 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// withMeta stores value under key in Meta. A nil Meta becomes a map; an existing
+// non-map Meta is converted through JSON, keeping its keys when it is an object and
+// replaced otherwise.
+func (r *APIResponse) withMeta(key string, value any) *APIResponse {
+	m, ok := asObject(r.Meta)
+	if !ok {
+		m = make(map[string]any)
+	}
+
+	m[key] = value
+	r.Meta = m
+	return r
+}
+
+// asObject returns v as a map to add keys to: a new map for nil, v itself for a
+// map[string]any, and the JSON form of v when it encodes to an object (e.g. a struct).
+// It reports false for any other value.
+func asObject(v any) (map[string]any, bool) {
+	if v == nil {
+		return make(map[string]any), true
+	}
+	if m, ok := v.(map[string]any); ok {
+		return m, true
+	}
+
+	normalized, err := normalizeJSON(v)
+	if err != nil {
+		return nil, false
+	}
+	m, ok := normalized.(map[string]any)
+	return m, ok
+}
+
+// RateLimit describes the rate-limit state of the client.
+//
+// Limit: Maximum number of requests allowed in the current window.
+// Remaining: Requests left in the current window.
+// Reset: When the current window resets.
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// WithRateLimit stores rl in Meta under `rateLimit`, for SDKs that don't read
+// headers, and makes Write emit the matching `X-RateLimit-*` headers.
+func (r *APIResponse) WithRateLimit(rl RateLimit) *APIResponse {
+	r.SetHeader("X-RateLimit-Limit", strconv.Itoa(rl.Limit))
+	r.SetHeader("X-RateLimit-Remaining", strconv.Itoa(rl.Remaining))
+	r.SetHeader("X-RateLimit-Reset", strconv.FormatInt(rl.Reset.Unix(), 10))
+	return r.withMeta("rateLimit", rl)
+}
+
+// RetryPolicy guides the backoff of clients retrying a failed request, e.g. a
+// transient 503.
+//
+// MaxRetries: Maximum number of retries the client should attempt.
+// BaseDelayMs: Initial delay in milliseconds, doubled on each retry.
+// Jitter: Whether the client should randomize each delay to spread out retries.
+type RetryPolicy struct {
+	MaxRetries  int  `json:"maxRetries"`
+	BaseDelayMs int  `json:"baseDelayMs"`
+	Jitter      bool `json:"jitter"`
+}
+
+// WithRetryPolicy stores p in Meta under `retryPolicy`, for SDKs to configure
+// their backoff from.
+func (r *APIResponse) WithRetryPolicy(p RetryPolicy) *APIResponse {
+	return r.withMeta("retryPolicy", p)
+}
+
+// Audit records who performed a mutation, what it was and when.
+//
+// Actor: Identity of the user or service that performed the action.
+// Action: What was done, e.g. "user.update".
+// At: When the action happened.
+type Audit struct {
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	At     time.Time `json:"at"`
+}
+
+// WithAudit stores a in Meta under `audit`, so clients and audit sinks can record
+// the actor and action directly from the response. Read it back with AuditMeta.
+func (r *APIResponse) WithAudit(a Audit) *APIResponse {
+	return r.withMeta("audit", a)
+}
+
+// AuditMeta returns the Audit stored in Meta under `audit`, as set by WithAudit.
+// It reports false when there is none.
+func (r *APIResponse) AuditMeta() (Audit, bool) {
+	meta, ok := MetaAs[struct {
+		Audit *Audit `json:"audit"`
+	}](r)
+	if !ok || meta.Audit == nil {
+		return Audit{}, false
+	}
+	return *meta.Audit, true
+}
+
+// WithExperimental flags the response as coming from an experimental feature whose
+// shape may change: Write emits an `X-Experimental: <feature>` header and Meta notes
+// the feature under `experimental`.
+func (r *APIResponse) WithExperimental(feature string) *APIResponse {
+	r.SetHeader("X-Experimental", feature)
+	return r.withMeta("experimental", feature)
+}
+
+// Pagination describes the position of a page within a paginated list.
+//
+// Page: Current page, starting at 1.
+// PerPage: Number of items per page.
+// TotalItems: Total number of items across all pages.
+// TotalPages: Total number of pages. Derived from TotalItems and PerPage when 0.
+type Pagination struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"perPage"`
+	TotalItems int `json:"totalItems"`
+	TotalPages int `json:"totalPages"`
+}
+
+// WithPaginationLinks stores p in Meta under `pagination` and generates the `self`,
+// `first`, `prev`, `next` and `last` links from baseURL with `page`/`perPage` query
+// parameters, omitting `prev` on the first page and `next` on the last. The links
+// are also emitted as a `Link` header (RFC 8288). Other query parameters of baseURL
+// are preserved; an unparsable baseURL leaves the response unchanged.
+func (r *APIResponse) WithPaginationLinks(baseURL string, p Pagination) *APIResponse {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return r
+	}
+
+	if p.TotalPages == 0 && p.PerPage > 0 {
+		p.TotalPages = (p.TotalItems + p.PerPage - 1) / p.PerPage
+	}
+	lastPage := max(p.TotalPages, 1)
+
+	pageURL := func(page int) string {
+		q := u.Query()
+		q.Set("page", strconv.Itoa(page))
+		q.Set("perPage", strconv.Itoa(p.PerPage))
+		pu := *u
+		pu.RawQuery = q.Encode()
+		return pu.String()
+	}
+
+	links := []struct {
+		rel  string
+		page int
+		ok   bool
+	}{
+		{"self", p.Page, true},
+		{"first", 1, true},
+		{"prev", p.Page - 1, p.Page > 1},
+		{"next", p.Page + 1, p.Page < lastPage},
+		{"last", lastPage, true},
+	}
+
+	if r.Links == nil {
+		r.Links = make(map[string]string)
+	}
+
+	var header []string
+	for _, link := range links {
+		if !link.ok {
+			continue
+		}
+		href := pageURL(link.page)
+		r.Links[link.rel] = href
+		header = append(header, fmt.Sprintf(`<%s>; rel="%s"`, href, link.rel))
+	}
+
+	r.SetHeader("Link", strings.Join(header, ", "))
+	return r.withMeta("pagination", p)
+}
+
+// MetaAs decodes Meta into T through a JSON round trip, e.g. to read typed metadata
+// after FromJsonToAPIResponse. It reports false when Meta is nil or doesn't fit T.
+func MetaAs[T any](r *APIResponse) (T, bool) {
+	var out T
+	if r.Meta == nil {
+		return out, false
+	}
+
+	b, err := json.Marshal(r.Meta)
+	if err != nil {
+		return out, false
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return out, false
+	}
+	return out, true
+}
+
+// PaginationMeta returns the Pagination stored in Meta under `pagination`, as set
+// by WithPaginationLinks. It reports false when there is none.
+func (r *APIResponse) PaginationMeta() (Pagination, bool) {
+	meta, ok := MetaAs[struct {
+		Pagination *Pagination `json:"pagination"`
+	}](r)
+	if !ok || meta.Pagination == nil {
+		return Pagination{}, false
+	}
+	return *meta.Pagination, true
+}
+
+// WithTiming reports how long the server spent on the request. Only when DebugMode
+// is on, it adds `processingMs` to Meta and makes Write emit a `Server-Timing`
+// header; otherwise the response is unchanged.
+func (r *APIResponse) WithTiming(d time.Duration) *APIResponse {
+	if !DebugMode {
+		return r
+	}
+
+	ms := float64(d.Microseconds()) / 1000
+	r.SetHeader("Server-Timing", "app;dur="+strconv.FormatFloat(ms, 'f', -1, 64))
+	return r.withMeta("processingMs", ms)
+}
@@ -0,0 +1,169 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_WithRateLimit(t *testing.T) {
+	reset := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := RateLimit{Limit: 100, Remaining: 7, Reset: reset}
+
+	rsp := List("", []int{1}, map[string]any{"page": 2}).WithRateLimit(rl)
+	assert.Equal(t, map[string]any{"page": 2, "rateLimit": rl}, rsp.Meta)
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, rsp.Write(rec))
+	assert.Equal(t, "100", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "7", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "1704067200", rec.Header().Get("X-RateLimit-Reset"))
+}
+
+func TestAPIResponse_WithRetryPolicy(t *testing.T) {
+	rsp := Error(http.StatusServiceUnavailable, "", "").
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelayMs: 200, Jitter: true})
+
+	v, err := rsp.ToJson()
+	assert.NoError(t, err)
+	assert.Contains(t, v, `"meta":{"retryPolicy":{"maxRetries":3,"baseDelayMs":200,"jitter":true}}`)
+}
+
+func TestAPIResponse_WithAudit(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	audit := Audit{Actor: "user_1", Action: "user.update", At: at}
+
+	v, err := OK("", nil).WithAudit(audit).ToJson()
+	assert.NoError(t, err)
+	assert.Contains(t, v, `"meta":{"audit":{"actor":"user_1","action":"user.update","at":"2024-01-01T12:00:00Z"}}`)
+
+	decoded, err := FromJsonToAPIResponse([]byte(v))
+	assert.NoError(t, err)
+	got, ok := decoded.AuditMeta()
+	assert.True(t, ok)
+	assert.Equal(t, audit, got)
+
+	_, ok = OK("", nil).AuditMeta()
+	assert.False(t, ok)
+
+	v, err = OK("", nil).ToJson()
+	assert.NoError(t, err)
+	assert.NotContains(t, v, "audit")
+}
+
+func TestAPIResponse_WithExperimental(t *testing.T) {
+	rec := httptest.NewRecorder()
+	assert.NoError(t, OK("", nil).WithExperimental("search-v2").Write(rec))
+
+	assert.Equal(t, "search-v2", rec.Header().Get("X-Experimental"))
+	assert.Contains(t, rec.Body.String(), `"meta":{"experimental":"search-v2"}`)
+}
+
+func TestAPIResponse_withMeta(t *testing.T) {
+	t.Run("nil meta", func(t *testing.T) {
+		rsp := OK("", nil).withMeta("k", "v")
+		assert.Equal(t, map[string]any{"k": "v"}, rsp.Meta)
+	})
+
+	t.Run("struct meta keeps its keys", func(t *testing.T) {
+		rsp := List("", nil, struct {
+			Page int `json:"page"`
+		}{Page: 3}).withMeta("k", "v")
+		assert.Equal(t, map[string]any{"page": float64(3), "k": "v"}, rsp.Meta)
+	})
+}
+
+func TestAPIResponse_WithPaginationLinks(t *testing.T) {
+	t.Run("middle page", func(t *testing.T) {
+		p := Pagination{Page: 2, PerPage: 10, TotalItems: 35}
+		rsp := List("", []int{}, nil).WithPaginationLinks("https://api.test/users?sort=name", p)
+
+		assert.Equal(t, map[string]string{
+			"self":  "https://api.test/users?page=2&perPage=10&sort=name",
+			"first": "https://api.test/users?page=1&perPage=10&sort=name",
+			"prev":  "https://api.test/users?page=1&perPage=10&sort=name",
+			"next":  "https://api.test/users?page=3&perPage=10&sort=name",
+			"last":  "https://api.test/users?page=4&perPage=10&sort=name",
+		}, rsp.Links)
+
+		p.TotalPages = 4
+		assert.Equal(t, map[string]any{"pagination": p}, rsp.Meta)
+		assert.Equal(t,
+			`<https://api.test/users?page=2&perPage=10&sort=name>; rel="self", `+
+				`<https://api.test/users?page=1&perPage=10&sort=name>; rel="first", `+
+				`<https://api.test/users?page=1&perPage=10&sort=name>; rel="prev", `+
+				`<https://api.test/users?page=3&perPage=10&sort=name>; rel="next", `+
+				`<https://api.test/users?page=4&perPage=10&sort=name>; rel="last"`,
+			rsp.Headers.Get("Link"),
+		)
+	})
+
+	t.Run("boundaries omit prev and next", func(t *testing.T) {
+		first := OK("", nil).WithPaginationLinks("/users", Pagination{Page: 1, PerPage: 10, TotalItems: 20})
+		assert.NotContains(t, first.Links, "prev")
+		assert.Contains(t, first.Links, "next")
+
+		last := OK("", nil).WithPaginationLinks("/users", Pagination{Page: 2, PerPage: 10, TotalItems: 20})
+		assert.Contains(t, last.Links, "prev")
+		assert.NotContains(t, last.Links, "next")
+	})
+}
+
+func TestMetaAs(t *testing.T) {
+	type pageMeta struct {
+		Page  int `json:"page"`
+		Total int `json:"total"`
+	}
+
+	v, err := List("", []int{}, map[string]any{"page": 2, "total": 40}).ToJson()
+	assert.NoError(t, err)
+	decoded, err := FromJsonToAPIResponse([]byte(v))
+	assert.NoError(t, err)
+
+	got, ok := MetaAs[pageMeta](decoded)
+	assert.True(t, ok)
+	assert.Equal(t, pageMeta{Page: 2, Total: 40}, got)
+
+	_, ok = MetaAs[pageMeta](OK("", nil))
+	assert.False(t, ok)
+
+	_, ok = MetaAs[[]int](decoded)
+	assert.False(t, ok)
+}
+
+func TestAPIResponse_PaginationMeta(t *testing.T) {
+	p := Pagination{Page: 1, PerPage: 20, TotalItems: 45, TotalPages: 3}
+	v, err := List("", []int{}, nil).WithPaginationLinks("/users", p).ToJson()
+	assert.NoError(t, err)
+
+	decoded, err := FromJsonToAPIResponse([]byte(v))
+	assert.NoError(t, err)
+
+	got, ok := decoded.PaginationMeta()
+	assert.True(t, ok)
+	assert.Equal(t, p, got)
+
+	_, ok = List("", nil, map[string]any{"page": 1}).PaginationMeta()
+	assert.False(t, ok)
+}
+
+func TestAPIResponse_WithTiming(t *testing.T) {
+	defer func(v bool) { DebugMode = v }(DebugMode)
+
+	t.Run("off outside debug mode", func(t *testing.T) {
+		DebugMode = false
+		rsp := OK("", nil).WithTiming(12 * time.Millisecond)
+		assert.Nil(t, rsp.Meta)
+		assert.Empty(t, rsp.Headers.Get("Server-Timing"))
+	})
+
+	t.Run("debug mode", func(t *testing.T) {
+		DebugMode = true
+		rsp := OK("", nil).WithTiming(12500 * time.Microsecond)
+		assert.Equal(t, map[string]any{"processingMs": 12.5}, rsp.Meta)
+		assert.Equal(t, "app;dur=12.5", rsp.Headers.Get("Server-Timing"))
+	})
+}
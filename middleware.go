@@ -0,0 +1,204 @@
+package response
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Store persists responses for IdempotencyMiddleware. Implementations must be
+// safe for concurrent use; how and for how long entries are kept is up to them.
+// Stores that serialize responses must keep StatusCode and Headers along with the
+// body, e.g. with ToByte: the fingerprint of the request body a response answered is
+// kept in its Headers, and a response restored without one is never replayed.
+type Store interface {
+	Get(key string) (*APIResponse, bool)
+	Set(key string, r *APIResponse)
+}
+
+// MarkReplayed flags the response as a replay of a previously stored result.
+// Write then emits the `Idempotent-Replayed: true` header.
+func (r *APIResponse) MarkReplayed() *APIResponse {
+	return r.SetHeader("Idempotent-Replayed", "true")
+}
+
+// IdempotencyMiddleware replays stored responses for mutating requests (POST, PUT,
+// PATCH, DELETE) carrying an `Idempotency-Key` header.
+//
+// The first request for a key is passed to the next handler and, if it wrote an
+// APIResponse with a non-5xx status, that response is saved in store along with a
+// fingerprint of the request body. Numbers in the stored response are kept exactly,
+// so a replay carries the same 64-bit IDs as the original. Later requests with the same method, path and
+// key receive the stored response marked as replayed, without reaching the next
+// handler. Server errors are not stored so clients may retry.
+//
+// While the first request for a key is running, concurrent requests with the same
+// key get a 409 instead of running the handler twice. Reusing a key with a different
+// body gets a 422 instead of the stored response. Requests are only tracked as in
+// progress within this middleware, so deployments running several instances should
+// route a key to a single instance.
+//
+// The first response is passed through as it is written, so streaming and flushing
+// keep working; only a copy of up to maxRecordedBody bytes is kept for storing, and
+// larger responses are not stored.
+func IdempotencyMiddleware(store Store) func(http.Handler) http.Handler {
+	var (
+		mu       sync.Mutex
+		inFlight = make(map[string]struct{})
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			idempotencyKey := req.Header.Get("Idempotency-Key")
+			if idempotencyKey == "" || !isMutatingMethod(req.Method) {
+				next.ServeHTTP(w, req)
+				return
+			}
+
+			key := req.Method + " " + req.URL.Path + " " + idempotencyKey
+			mu.Lock()
+			if _, busy := inFlight[key]; busy {
+				mu.Unlock()
+				_ = Conflict("A request with this Idempotency-Key is still in progress", "IDEMPOTENCY_KEY_IN_USE").Write(w)
+				return
+			}
+			inFlight[key] = struct{}{}
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				delete(inFlight, key)
+				mu.Unlock()
+			}()
+
+			fingerprint, err := bodyFingerprint(req)
+			if err != nil {
+				_ = BadRequest("Request body could not be read", "INVALID_BODY").Write(w)
+				return
+			}
+
+			if stored, ok := store.Get(key); ok {
+				if stored.Headers.Get(fingerprintHeader) != fingerprint {
+					_ = Error(http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request body", "IDEMPOTENCY_KEY_REUSED").Write(w)
+					return
+				}
+				replay := stored.clone()
+				replay.Headers.Del(fingerprintHeader)
+				_ = replay.MarkReplayed().Write(w)
+				return
+			}
+
+			rec := &recordingWriter{ResponseWriter: w}
+			next.ServeHTTP(rec, req)
+
+			if rec.StatusCode() >= http.StatusInternalServerError {
+				return
+			}
+			if rsp, ok := rec.Response(); ok {
+				rsp.SetHeader(fingerprintHeader, fingerprint)
+				store.Set(key, rsp)
+			}
+		})
+	}
+}
+
+// fingerprintHeader holds the request body fingerprint in a stored response's
+// Headers. It is removed before the response is replayed.
+const fingerprintHeader = "Idempotency-Fingerprint"
+
+// bodyFingerprint returns the hex SHA-256 of the request body and restores the body
+// for the next handler.
+func bodyFingerprint(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return hex.EncodeToString(sha256.New().Sum(nil)), nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// maxRecordedBody bounds the body IdempotencyMiddleware keeps to store a response.
+const maxRecordedBody = 1 << 20
+
+// recordingWriter passes a response through to the underlying writer while keeping
+// a copy of its status and body, up to maxRecordedBody bytes.
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+	overflow   bool
+}
+
+func (w *recordingWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	if !w.overflow {
+		if w.body.Len()+len(p) > maxRecordedBody {
+			w.overflow = true
+			w.body = bytes.Buffer{}
+		} else {
+			w.body.Write(p)
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// StatusCode returns the status code written, 200 if none was written.
+func (w *recordingWriter) StatusCode() int {
+	if w.statusCode == 0 {
+		return http.StatusOK
+	}
+	return w.statusCode
+}
+
+// Flush flushes the underlying writer when it supports it.
+func (w *recordingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController.
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Response decodes the recorded body as an APIResponse, with StatusCode and Headers
+// set from the written status and headers. It reports false when the body was too
+// large to record or isn't an APIResponse. Numbers are decoded as json.Number, so
+// they encode back exactly.
+func (w *recordingWriter) Response() (*APIResponse, bool) {
+	if w.overflow {
+		return nil, false
+	}
+	rsp := &APIResponse{}
+	if err := rsp.unmarshalJSON(w.body.Bytes(), true); err != nil {
+		return nil, false
+	}
+	rsp.StatusCode = w.StatusCode()
+	rsp.Headers = w.Header().Clone()
+	return rsp, true
+}
@@ -0,0 +1,235 @@
+package response
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mapStore struct {
+	mu sync.Mutex
+	m  map[string]*APIResponse
+}
+
+func (s *mapStore) Get(key string) (*APIResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.m[key]
+	return r, ok
+}
+
+func (s *mapStore) Set(key string, r *APIResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = r
+}
+
+// serializingStore keeps responses as their status, headers and JSON body, like a
+// store backed by Redis or SQL would.
+type serializingStore struct {
+	mu sync.Mutex
+	m  map[string]storedResponse
+}
+
+type storedResponse struct {
+	status int
+	header http.Header
+	body   string
+}
+
+func (s *serializingStore) Get(key string) (*APIResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.m[key]
+	if !ok {
+		return nil, false
+	}
+	r, err := FromResponseBytes(stored.status, stored.header, []byte(stored.body))
+	return r, err == nil
+}
+
+func (s *serializingStore) Set(key string, r *APIResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := r.ToJson()
+	s.m[key] = storedResponse{status: r.StatusCode, header: r.Headers.Clone(), body: body}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	calls := 0
+	handler := IdempotencyMiddleware(&mapStore{m: map[string]*APIResponse{}})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			_ = Created("payment created", map[string]any{"id": "pay_1"}).Write(w)
+		}),
+	)
+
+	send := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set("Idempotency-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("k1")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+	second := send("k1")
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, 1, calls)
+
+	send("k2")
+	assert.Equal(t, 2, calls)
+
+	t.Run("responses stream through", func(t *testing.T) {
+		handler := IdempotencyMiddleware(&mapStore{m: map[string]*APIResponse{}})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("chunk"))
+				assert.NoError(t, http.NewResponseController(w).Flush())
+			}),
+		)
+
+		req := httptest.NewRequest(http.MethodPost, "/events", nil)
+		req.Header.Set("Idempotency-Key", "k3")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.True(t, rec.Flushed)
+		assert.Equal(t, "chunk", rec.Body.String())
+	})
+
+	t.Run("concurrent requests with the same key", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		handler := IdempotencyMiddleware(&mapStore{m: map[string]*APIResponse{}})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				<-release
+				_ = Created("payment created", nil).Write(w)
+			}),
+		)
+
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set("Idempotency-Key", "k4")
+
+		first := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			handler.ServeHTTP(first, req.Clone(req.Context()))
+			close(done)
+		}()
+		<-started
+
+		second := httptest.NewRecorder()
+		handler.ServeHTTP(second, req.Clone(req.Context()))
+		assert.Equal(t, http.StatusConflict, second.Code)
+		assert.Contains(t, second.Body.String(), `"errorCode":"IDEMPOTENCY_KEY_IN_USE"`)
+
+		close(release)
+		<-done
+		assert.Equal(t, http.StatusCreated, first.Code)
+
+		third := httptest.NewRecorder()
+		handler.ServeHTTP(third, req.Clone(req.Context()))
+		assert.Equal(t, http.StatusCreated, third.Code)
+		assert.Equal(t, "true", third.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("same key with a different body", func(t *testing.T) {
+		var bodies []string
+		handler := IdempotencyMiddleware(&mapStore{m: map[string]*APIResponse{}})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var sb strings.Builder
+				_, _ = io.Copy(&sb, r.Body)
+				bodies = append(bodies, sb.String())
+				_ = Created("payment created", nil).Write(w)
+			}),
+		)
+
+		send := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+			req.Header.Set("Idempotency-Key", "k5")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+
+		assert.Equal(t, http.StatusCreated, send(`{"amount":100}`).Code)
+		assert.Equal(t, "true", send(`{"amount":100}`).Header().Get("Idempotent-Replayed"))
+
+		reused := send(`{"amount":999}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+		assert.Contains(t, reused.Body.String(), `"errorCode":"IDEMPOTENCY_KEY_REUSED"`)
+		assert.Equal(t, []string{`{"amount":100}`}, bodies)
+	})
+
+	t.Run("unreadable body", func(t *testing.T) {
+		defer func(v bool) { RequireErrorCode = v }(RequireErrorCode)
+		RequireErrorCode = true
+
+		handler := IdempotencyMiddleware(&mapStore{m: map[string]*APIResponse{}})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Fatal("handler must not run")
+			}),
+		)
+
+		req := httptest.NewRequest(http.MethodPost, "/payments", iotest.ErrReader(errors.New("connection reset")))
+		req.Header.Set("Idempotency-Key", "k6")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"errorCode":"INVALID_BODY"`)
+	})
+
+	t.Run("replays keep 64-bit ids", func(t *testing.T) {
+		handler := IdempotencyMiddleware(&mapStore{m: map[string]*APIResponse{}})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = Created("payment created", map[string]any{"id": int64(9007199254740993)}).Write(w)
+			}),
+		)
+
+		var bodies []string
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+			req.Header.Set("Idempotency-Key", "k7")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			bodies = append(bodies, rec.Body.String())
+		}
+		assert.Contains(t, bodies[1], `"id":9007199254740993`)
+		assert.JSONEq(t, bodies[0], bodies[1])
+	})
+
+	t.Run("fingerprint survives a serializing store", func(t *testing.T) {
+		handler := IdempotencyMiddleware(&serializingStore{m: map[string]storedResponse{}})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = Created("payment created", nil).Write(w)
+			}),
+		)
+
+		send := func(body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+			req.Header.Set("Idempotency-Key", "k8")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+
+		send(`{"amount":100}`)
+		replay := send(`{"amount":100}`)
+		assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+		assert.Empty(t, replay.Header().Get("Idempotency-Fingerprint"))
+		assert.Equal(t, http.StatusUnprocessableEntity, send(`{"amount":999}`).Code)
+	})
+}
@@ -0,0 +1,98 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is a monetary amount that keeps its exact value through JSON, unlike a
+// float64 (which is what amounts become when they pass through `any`). Use it for
+// amounts in Data:
+//
+//	response.OK("", Order{Total: response.Money{Amount: 1234, Currency: "USD"}})
+//
+// encodes the total as `{"amount": "12.34", "currency": "USD"}`.
+//
+// Amount: Value in the currency's minor unit, e.g. cents for USD.
+// Currency: ISO 4217 currency code. It sets the number of decimals: 2 by default,
+// 0 for currencies such as JPY and 3 for currencies such as KWD.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// moneyDecimals lists the ISO 4217 currencies whose minor unit isn't 1/100.
+var moneyDecimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+func (m Money) decimals() int {
+	if d, ok := moneyDecimals[strings.ToUpper(m.Currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// String formats the amount as a decimal followed by the currency, e.g. "12.34 USD".
+func (m Money) String() string {
+	return m.decimal() + " " + m.Currency
+}
+
+// decimal formats the amount as a decimal string, e.g. "-12.34".
+func (m Money) decimal() string {
+	digits := strconv.FormatInt(m.Amount, 10)
+	sign := ""
+	if m.Amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	d := m.decimals()
+	if d == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d {
+		digits = strings.Repeat("0", d-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-d] + "." + digits[len(digits)-d:]
+}
+
+// MarshalJSON encodes the amount as a decimal string, so no precision is lost.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.decimal(), m.Currency})
+}
+
+// UnmarshalJSON decodes an amount given as a decimal string or number, rejecting
+// amounts with more decimals than the currency allows.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	out := Money{Currency: v.Currency}
+	whole, frac, _ := strings.Cut(string(v.Amount), ".")
+	d := out.decimals()
+	if len(frac) > d {
+		return fmt.Errorf("response: money amount %q has more than %d decimals for %s", v.Amount, d, v.Currency)
+	}
+	frac += strings.Repeat("0", d-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || strings.ContainsAny(whole, "eE") {
+		return fmt.Errorf("response: invalid money amount %q", v.Amount)
+	}
+	out.Amount = amount
+
+	*m = out
+	return nil
+}
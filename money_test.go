@@ -0,0 +1,47 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		money Money
+		json  string
+	}{
+		{Money{Amount: 1234, Currency: "USD"}, `{"amount":"12.34","currency":"USD"}`},
+		{Money{Amount: 5, Currency: "EUR"}, `{"amount":"0.05","currency":"EUR"}`},
+		{Money{Amount: -1999, Currency: "USD"}, `{"amount":"-19.99","currency":"USD"}`},
+		{Money{Amount: 1500, Currency: "JPY"}, `{"amount":"1500","currency":"JPY"}`},
+		{Money{Amount: 1234, Currency: "KWD"}, `{"amount":"1.234","currency":"KWD"}`},
+		{Money{Amount: 9007199254740993, Currency: "USD"}, `{"amount":"90071992547409.93","currency":"USD"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.money.String(), func(t *testing.T) {
+			b, err := json.Marshal(tt.money)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.json, string(b))
+
+			var got Money
+			assert.NoError(t, json.Unmarshal(b, &got))
+			assert.Equal(t, tt.money, got)
+		})
+	}
+
+	t.Run("decodes numbers and short decimals", func(t *testing.T) {
+		var got Money
+		assert.NoError(t, json.Unmarshal([]byte(`{"amount":12.5,"currency":"USD"}`), &got))
+		assert.Equal(t, Money{Amount: 1250, Currency: "USD"}, got)
+	})
+
+	t.Run("invalid amounts", func(t *testing.T) {
+		var got Money
+		assert.ErrorContains(t, json.Unmarshal([]byte(`{"amount":"1.234","currency":"USD"}`), &got), "more than 2 decimals")
+		assert.ErrorContains(t, json.Unmarshal([]byte(`{"amount":"1e3","currency":"USD"}`), &got), "invalid money amount")
+		assert.Error(t, json.Unmarshal([]byte(`{"amount":"abc","currency":"USD"}`), &got))
+	})
+}
@@ -0,0 +1,59 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to original and returns the
+// merged value in its generic JSON form (maps, slices, json.Number, ...), ready to
+// be echoed back as Data. Numbers are kept exactly, so 64-bit IDs in original or
+// patch are not rounded. Keys set to null in the patch are removed; objects are merged
+// recursively and any other value replaces the target.
+//
+// A malformed patch yields a BadRequest describing the JSON problem; an original that
+// cannot be marshaled yields an InternalServerError.
+func ApplyMergePatch(original any, patch []byte) (any, *APIResponse) {
+	target, err := normalizeJSONExact(original)
+	if err != nil {
+		return nil, InternalServerError("", "INTERNAL")
+	}
+
+	var raw json.RawMessage
+	if err := json.Unmarshal(patch, &raw); err != nil {
+		if _, details := IsJsonErrorGetDetails(err); details != nil {
+			return nil, BadRequest("Invalid merge patch: "+details.Error(), "INVALID_PATCH")
+		}
+		return nil, BadRequest("Invalid merge patch", "INVALID_PATCH")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var p any
+	if err := dec.Decode(&p); err != nil {
+		return nil, BadRequest("Invalid merge patch", "INVALID_PATCH")
+	}
+
+	return mergePatch(target, p), nil
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = mergePatch(t[key], value)
+	}
+	return t
+}
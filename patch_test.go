@@ -0,0 +1,55 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	type address struct {
+		City    string `json:"city"`
+		Country string `json:"country"`
+	}
+	type user struct {
+		Name    string   `json:"name"`
+		Tags    []string `json:"tags"`
+		Address address  `json:"address"`
+	}
+	original := user{Name: "john", Tags: []string{"a", "b"}, Address: address{City: "Lagos", Country: "NG"}}
+
+	t.Run("merges recursively", func(t *testing.T) {
+		got, rsp := ApplyMergePatch(original, []byte(`{"name":"jane","tags":["c"],"address":{"city":null,"zip":"100001"}}`))
+		assert.Nil(t, rsp)
+		assert.Equal(t, map[string]any{
+			"name":    "jane",
+			"tags":    []any{"c"},
+			"address": map[string]any{"country": "NG", "zip": "100001"},
+		}, got)
+	})
+
+	t.Run("non-object patch replaces the target", func(t *testing.T) {
+		got, rsp := ApplyMergePatch(original, []byte(`["x"]`))
+		assert.Nil(t, rsp)
+		assert.Equal(t, []any{"x"}, got)
+	})
+
+	t.Run("64-bit ids kept exactly", func(t *testing.T) {
+		got, rsp := ApplyMergePatch(map[string]any{"id": int64(9007199254740993), "name": "john"}, []byte(`{"name":"jane","parentId":9007199254740995}`))
+		assert.Nil(t, rsp)
+		assert.Equal(t, map[string]any{
+			"id":       json.Number("9007199254740993"),
+			"name":     "jane",
+			"parentId": json.Number("9007199254740995"),
+		}, got)
+	})
+
+	t.Run("malformed patch", func(t *testing.T) {
+		got, rsp := ApplyMergePatch(original, []byte(`{"name":`))
+		assert.Nil(t, got)
+		assert.Equal(t, http.StatusBadRequest, rsp.StatusCode)
+		assert.Equal(t, "Invalid merge patch: body contains badly-formed JSON (at character 8)", rsp.Message)
+	})
+}
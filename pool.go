@@ -0,0 +1,30 @@
+package response
+
+import "sync"
+
+var responsePool = sync.Pool{
+	New: func() any { return new(APIResponse) },
+}
+
+// AcquireResponse returns an empty APIResponse from a pool, with only Version set.
+// Pair it with ReleaseResponse on hot paths where allocating the envelope shows up
+// in profiles.
+func AcquireResponse() *APIResponse {
+	r := responsePool.Get().(*APIResponse)
+	r.Version = EnvelopeVersion
+	return r
+}
+
+// ReleaseResponse resets r and returns it to the pool.
+//
+// Lifetime contract: r must not be used, retained or referenced (including its
+// Headers, Details or other maps and slices) after release, since it will be handed
+// out again by AcquireResponse. Only release a response once it has been fully
+// written, e.g. after Write returns. Nothing in this package releases responses for you.
+func ReleaseResponse(r *APIResponse) {
+	if r == nil {
+		return
+	}
+	*r = APIResponse{}
+	responsePool.Put(r)
+}
@@ -0,0 +1,53 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireResponse(t *testing.T) {
+	r := AcquireResponse()
+	assert.Equal(t, &APIResponse{Version: EnvelopeVersion}, r)
+
+	r.StatusCode = http.StatusOK
+	r.Message = "pooled"
+	r.SetHeader("X-A", "1")
+	ReleaseResponse(r)
+	assert.Equal(t, &APIResponse{}, r)
+
+	assert.NotPanics(t, func() { ReleaseResponse(nil) })
+}
+
+type benchUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var benchUserData any = benchUser{ID: 1, Name: "john"}
+
+func BenchmarkHandler_NewResponse(b *testing.B) {
+	buf := make([]byte, 0, 512)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rsp := OK("fetched", benchUserData)
+		buf, _ = rsp.AppendJSON(buf[:0])
+	}
+}
+
+func BenchmarkHandler_PooledResponse(b *testing.B) {
+	buf := make([]byte, 0, 512)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rsp := AcquireResponse()
+		rsp.StatusCode = http.StatusOK
+		rsp.Success = true
+		rsp.Message = "fetched"
+		rsp.Data = benchUserData
+		buf, _ = rsp.AppendJSON(buf[:0])
+		ReleaseResponse(rsp)
+	}
+}
@@ -0,0 +1,98 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// VolatileFields lists the values Snapshot drops because they change from run to
+// run. Nested keys are named with dots, e.g. "meta.processingMs".
+var VolatileFields = []string{"requestId", "meta.processingMs"}
+
+// Snapshot returns the response as stable, indented JSON suitable for committing
+// as a golden file: keys are sorted and the fields listed in VolatileFields are
+// dropped. Compare it against the golden file with CompareSnapshot.
+func (r *APIResponse) Snapshot() ([]byte, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeSnapshot(b)
+}
+
+// CompareSnapshot reports whether two snapshots hold the same JSON, ignoring key
+// order and formatting. When they differ it also returns a line diff of the
+// normalized snapshots, with `-` marking golden lines and `+` actual ones.
+func CompareSnapshot(golden, actual []byte) (bool, string) {
+	g, err := normalizeSnapshot(golden)
+	if err != nil {
+		return false, fmt.Sprintf("invalid golden snapshot: %v", err)
+	}
+	a, err := normalizeSnapshot(actual)
+	if err != nil {
+		return false, fmt.Sprintf("invalid actual snapshot: %v", err)
+	}
+
+	if bytes.Equal(g, a) {
+		return true, ""
+	}
+	return false, lineDiff(strings.Split(string(g), "\n"), strings.Split(string(a), "\n"))
+}
+
+// normalizeSnapshot re-encodes JSON with sorted keys and indentation, without
+// the volatile fields. Numbers are kept as written, so 64-bit IDs differing beyond
+// float64 precision still compare unequal.
+func normalizeSnapshot(b []byte) ([]byte, error) {
+	var v any
+	if err := decodeJSON(b, &v, true); err != nil {
+		return nil, err
+	}
+
+	for _, path := range VolatileFields {
+		keys := strings.Split(path, ".")
+		obj, _ := v.(map[string]any)
+		for _, key := range keys[:len(keys)-1] {
+			obj, _ = obj[key].(map[string]any)
+		}
+		delete(obj, keys[len(keys)-1])
+	}
+
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// lineDiff lists the lines removed from a (`- `) and added in b (`+ `), keeping
+// the common lines (`  `) as context, based on their longest common subsequence.
+func lineDiff(a, b []string) string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
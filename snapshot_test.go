@@ -0,0 +1,57 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_Snapshot(t *testing.T) {
+	rsp := OK("fetched", map[string]any{"name": "john", "id": 1}).WithRequestID("req-1")
+	rsp.Meta = map[string]any{"processingMs": 12.5, "page": 1}
+
+	b, err := rsp.Snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "data": {
+    "id": 1,
+    "name": "john"
+  },
+  "message": "fetched",
+  "meta": {
+    "page": 1
+  },
+  "success": true,
+  "version": "1"
+}`, string(b))
+}
+
+func TestCompareSnapshot(t *testing.T) {
+	t.Run("equal ignoring order and formatting", func(t *testing.T) {
+		ok, diff := CompareSnapshot([]byte(`{"a":1,"b":[1,2]}`), []byte("{\n\"b\": [1, 2], \"a\": 1}"))
+		assert.True(t, ok)
+		assert.Empty(t, diff)
+	})
+
+	t.Run("different", func(t *testing.T) {
+		ok, diff := CompareSnapshot([]byte(`{"a":1,"b":2}`), []byte(`{"a":1,"b":3}`))
+		assert.False(t, ok)
+		assert.Equal(t, "  {\n    \"a\": 1,\n-   \"b\": 2\n+   \"b\": 3\n  }\n", diff)
+	})
+
+	t.Run("64-bit ids compared exactly", func(t *testing.T) {
+		ok, diff := CompareSnapshot([]byte(`{"id":9007199254740993}`), []byte(`{"id":9007199254740992}`))
+		assert.False(t, ok)
+		assert.Contains(t, diff, `-   "id": 9007199254740993`)
+
+		snap, err := OK("", map[string]any{"id": int64(9007199254740993)}).Snapshot()
+		assert.NoError(t, err)
+		assert.Contains(t, string(snap), `"id": 9007199254740993`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ok, diff := CompareSnapshot([]byte(`{`), []byte(`{}`))
+		assert.False(t, ok)
+		assert.Contains(t, diff, "invalid golden snapshot")
+	})
+}
@@ -0,0 +1,376 @@
+package response
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// TimeLayout selects how time.Time values are encoded, see TimeFormat.
+type TimeLayout int
+
+const (
+	// TimeRFC3339 keeps the encoding/json default: RFC 3339 with as many fractional
+	// second digits as needed, e.g. "2024-01-02T15:04:05.123456789Z".
+	TimeRFC3339 TimeLayout = iota
+	// TimeRFC3339Millis encodes RFC 3339 with exactly millisecond precision,
+	// e.g. "2024-01-02T15:04:05.123Z".
+	TimeRFC3339Millis
+	// TimeUnixMillis encodes milliseconds since the Unix epoch as a number.
+	TimeUnixMillis
+)
+
+// TimeFormat is the layout MarshalJSON applies to every time.Time found in Data,
+// Meta and Details, including inside nested structs, maps and slices, for partners
+// whose parsers can't handle the default. Values with their own MarshalJSON or
+// MarshalText (other than time.Time) are left to it.
+var TimeFormat = TimeRFC3339
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// formatTimes returns v in a generic form (maps, slices and leaf values) encoding
+// the same JSON, with time.Time values converted according to TimeFormat. Like
+// encoding/json, it returns a *json.UnsupportedValueError for cyclic values instead
+// of recursing forever.
+func formatTimes(v reflect.Value) (any, error) {
+	f := timeFormatter{visiting: make(map[visit]bool)}
+	return f.format(v)
+}
+
+// visit identifies a pointer, map or slice being formatted, see timeFormatter.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// timeFormatter tracks the pointers, maps and slices on the current path, so a
+// value reached again through itself is reported as a cycle.
+type timeFormatter struct {
+	visiting map[visit]bool
+}
+
+// enter marks v as being formatted, failing when it already is. The returned
+// function unmarks it.
+func (f *timeFormatter) enter(v reflect.Value) (func(), error) {
+	key := visit{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	if f.visiting[key] {
+		return nil, &json.UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())}
+	}
+	f.visiting[key] = true
+	return func() { delete(f.visiting, key) }, nil
+}
+
+func (f *timeFormatter) format(v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	t := v.Type()
+	switch {
+	case t == timeType:
+		tm := v.Interface().(time.Time)
+		if TimeFormat == TimeUnixMillis {
+			return tm.UnixMilli(), nil
+		}
+		return tm.Format("2006-01-02T15:04:05.000Z07:00"), nil
+	case t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface &&
+		(t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)):
+		return v.Interface(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Kind() == reflect.Pointer && t.Elem() != timeType &&
+			(t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)) {
+			return v.Interface(), nil
+		}
+		if t.Kind() == reflect.Pointer {
+			leave, err := f.enter(v)
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+		return f.format(v.Elem())
+
+	case reflect.Struct:
+		out := make(map[string]any)
+		if err := f.formatStruct(v, out); err != nil {
+			return nil, err
+		}
+		return out, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		leave, err := f.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer leave()
+
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value, err := f.format(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			out[mapKeyString(iter.Key())] = value
+		}
+		return out, nil
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 || (t.Kind() == reflect.Slice && v.IsNil()) {
+			return v.Interface(), nil
+		}
+		if t.Kind() == reflect.Slice {
+			leave, err := f.enter(v)
+			if err != nil {
+				return nil, err
+			}
+			defer leave()
+		}
+
+		out := make([]any, v.Len())
+		for i := range out {
+			value, err := f.format(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = value
+		}
+		return out, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// formatStruct adds the JSON fields of struct v to out, as resolved by
+// structJSONFields, honoring the omitempty, omitzero and string options.
+func (f *timeFormatter) formatStruct(v reflect.Value, out map[string]any) error {
+	for _, field := range structJSONFields(v.Type()) {
+		fv, ok := fieldByIndex(v, field.index)
+		if !ok {
+			continue
+		}
+		if (field.omitEmpty && isEmptyValue(fv)) || (field.omitZero && isZeroValue(fv)) {
+			continue
+		}
+		if field.quoted {
+			out[field.name] = quotedValue(fv)
+			continue
+		}
+		value, err := f.format(fv)
+		if err != nil {
+			return err
+		}
+		out[field.name] = value
+	}
+	return nil
+}
+
+// jsonField is a struct field as encoding/json encodes it.
+type jsonField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool
+}
+
+// structJSONFields returns the fields encoding/json encodes for struct type t:
+// exported fields named by their json tag, with the fields of untagged embedded
+// structs promoted. When several fields share a name, the shallowest wins, then a
+// tagged one; fields that remain ambiguous are dropped, as encoding/json does.
+func structJSONFields(t reflect.Type) []jsonField {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []jsonField
+	depths := make(map[string]int)
+	visited := make(map[reflect.Type]bool)
+
+	current := []embedded{{typ: t}}
+	for depth := 0; len(current) > 0; depth++ {
+		var next []embedded
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+				if sf.Tag.Get("json") == "-" {
+					continue
+				}
+
+				name, opts := parseJSONTag(sf)
+				index := append(slices.Clone(e.index), i)
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: ft, index: index})
+					continue
+				}
+
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+				}
+				if _, seen := depths[name]; !seen {
+					depths[name] = depth
+				}
+				fields = append(fields, jsonField{
+					name:      name,
+					index:     index,
+					tagged:    tagged,
+					omitEmpty: slices.Contains(opts, "omitempty"),
+					omitZero:  slices.Contains(opts, "omitzero"),
+					quoted:    slices.Contains(opts, "string") && quotable(ft),
+				})
+			}
+		}
+		for _, e := range current {
+			visited[e.typ] = true
+		}
+		current = next
+	}
+
+	// Keep the dominant field of each name: the only one at the shallowest depth, or
+	// the only tagged one there.
+	byName := make(map[string][]jsonField)
+	for _, f := range fields {
+		if len(f.index)-1 == depths[f.name] {
+			byName[f.name] = append(byName[f.name], f)
+		}
+	}
+
+	var dominant []jsonField
+	for _, candidates := range byName {
+		if len(candidates) == 1 {
+			dominant = append(dominant, candidates[0])
+			continue
+		}
+		var tagged []jsonField
+		for _, f := range candidates {
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+		if len(tagged) == 1 {
+			dominant = append(dominant, tagged[0])
+		}
+	}
+	return dominant
+}
+
+// quotable reports whether the string option applies to fields of type t.
+func quotable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
+// quotedValue encodes v as a JSON string holding its JSON encoding, as the string
+// option does.
+func quotedValue(v reflect.Value) any {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return v.Interface()
+	}
+	return string(b)
+}
+
+// fieldByIndex returns the nested field of v at index, reporting false when it sits
+// behind a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// mapKeyString encodes a map key the way encoding/json does.
+func mapKeyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if b, err := tm.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+// isEmptyValue reports whether v is empty in the sense of the omitempty option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isZeroValue reports whether v is zero in the sense of the omitzero option, using
+// its IsZero method when it has one.
+func isZeroValue(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return true
+		}
+		return z.IsZero()
+	}
+	return v.IsZero()
+}
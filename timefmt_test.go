@@ -0,0 +1,144 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeFormat(t *testing.T) {
+	defer func(v TimeLayout) { TimeFormat = v }(TimeFormat)
+
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 123456789, time.UTC)
+
+	type audit struct {
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	type event struct {
+		audit
+		ID        int        `json:"id"`
+		DeletedAt *time.Time `json:"deletedAt,omitempty"`
+		Skipped   time.Time  `json:"-"`
+		Tags      []string   `json:"tags"`
+		Times     map[int]time.Time
+	}
+	data := event{audit: audit{CreatedAt: ts}, ID: 1, Times: map[int]time.Time{1: ts}}
+
+	encode := func() string {
+		rsp := OK("", data).WithDetail("at", &ts)
+		rsp.Meta = map[string]any{"generatedAt": ts}
+		b, err := json.Marshal(rsp)
+		assert.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		TimeFormat = TimeRFC3339
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful",
+			"data":{"createdAt":"2024-01-02T15:04:05.123456789Z","id":1,"tags":null,"Times":{"1":"2024-01-02T15:04:05.123456789Z"}},
+			"meta":{"generatedAt":"2024-01-02T15:04:05.123456789Z"},
+			"details":{"at":"2024-01-02T15:04:05.123456789Z"}}`, encode())
+	})
+
+	t.Run("millisecond precision", func(t *testing.T) {
+		TimeFormat = TimeRFC3339Millis
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful",
+			"data":{"createdAt":"2024-01-02T15:04:05.123Z","id":1,"tags":null,"Times":{"1":"2024-01-02T15:04:05.123Z"}},
+			"meta":{"generatedAt":"2024-01-02T15:04:05.123Z"},
+			"details":{"at":"2024-01-02T15:04:05.123Z"}}`, encode())
+	})
+
+	t.Run("unix milliseconds", func(t *testing.T) {
+		TimeFormat = TimeUnixMillis
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful",
+			"data":{"createdAt":1704207845123,"id":1,"tags":null,"Times":{"1":1704207845123}},
+			"meta":{"generatedAt":1704207845123},
+			"details":{"at":1704207845123}}`, encode())
+	})
+
+	t.Run("fields resolve like encoding/json", func(t *testing.T) {
+		TimeFormat = TimeRFC3339Millis
+
+		type named struct {
+			Name string `json:"name"`
+			At   time.Time
+		}
+		type other struct{ At time.Time }
+		type tagged struct {
+			ID string `json:"ID"`
+		}
+		type untagged struct{ ID string }
+		type item struct {
+			named
+			other
+			untagged
+			tagged
+			*audit
+			Count  int        `json:"count,string"`
+			Label  string     `json:"label,string"`
+			Ptr    *int       `json:"ptr,string"`
+			Zero   time.Time  `json:"zero,omitzero"`
+			Empty  []int      `json:"empty,omitempty"`
+			Nested *named     `json:"nested"`
+			Plain  *time.Time `json:"plain"`
+		}
+
+		at := time.Date(2024, 1, 2, 15, 4, 5, 123000000, time.UTC)
+		n := 7
+		data := item{
+			named:    named{Name: "a", At: at},
+			other:    other{At: at},
+			tagged:   tagged{ID: "t"},
+			untagged: untagged{ID: "u"},
+			Count:    3,
+			Label:    "x",
+			Ptr:      &n,
+			Nested:   &named{Name: "n", At: at},
+			Plain:    &at,
+		}
+
+		want, err := json.Marshal(data)
+		assert.NoError(t, err)
+		generic, err := formatTimes(reflect.ValueOf(data))
+		assert.NoError(t, err)
+		got, err := json.Marshal(generic)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(want), string(got))
+	})
+
+	t.Run("cyclic data falls back to SERIALIZATION_ERROR", func(t *testing.T) {
+		type node struct {
+			At   time.Time `json:"at"`
+			Next *node     `json:"next"`
+		}
+		loop := &node{}
+		loop.Next = loop
+		m := map[string]any{}
+		m["self"] = m
+		TimeFormat = TimeUnixMillis
+
+		for _, data := range []any{loop, m} {
+			_, err := formatTimes(reflect.ValueOf(data))
+			var unsupported *json.UnsupportedValueError
+			assert.ErrorAs(t, err, &unsupported)
+
+			rec := httptest.NewRecorder()
+			assert.NoError(t, OK("", data).Write(rec))
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"errorCode":"SERIALIZATION_ERROR"`)
+		}
+	})
+
+	t.Run("shared values are not cycles", func(t *testing.T) {
+		shared := &struct {
+			At time.Time `json:"at"`
+		}{}
+		_, err := formatTimes(reflect.ValueOf([]any{shared, shared, map[string]any{"a": shared}}))
+		assert.NoError(t, err)
+	})
+}
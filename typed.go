@@ -0,0 +1,40 @@
+package response
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// RecordDataTypes makes Typed record the Go type of every Data it sees, for
+// client SDK generators to read back with RegisteredDataTypes. Enable it only in
+// the generation run (e.g. while exercising handlers in tests).
+var RecordDataTypes bool
+
+var (
+	dataTypesMu sync.RWMutex
+	dataTypes   = map[string]reflect.Type{}
+)
+
+// Typed creates a success response whose Data is tagged with typeName in DataType,
+// the logical name client SDKs generate the matching model under. Under
+// RecordDataTypes the type of data is also recorded against typeName.
+//
+// return Typed(http.StatusOK, "", "user", user)
+func Typed(statusCode int, msg, typeName string, data any) *APIResponse {
+	if RecordDataTypes && data != nil {
+		dataTypesMu.Lock()
+		dataTypes[typeName] = reflect.TypeOf(data)
+		dataTypesMu.Unlock()
+	}
+
+	return Success(statusCode, msg, data).WithDataType(typeName)
+}
+
+// RegisteredDataTypes returns a copy of the type names recorded by Typed, mapped
+// to the Go type of their Data.
+func RegisteredDataTypes() map[string]reflect.Type {
+	dataTypesMu.RLock()
+	defer dataTypesMu.RUnlock()
+	return maps.Clone(dataTypes)
+}
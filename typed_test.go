@@ -0,0 +1,30 @@
+package response
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTyped(t *testing.T) {
+	type user struct {
+		ID int `json:"id"`
+	}
+
+	t.Run("sets the data type", func(t *testing.T) {
+		rsp := Typed(http.StatusOK, "", "user", user{ID: 1})
+		assert.Equal(t, "user", rsp.DataType)
+		assert.True(t, rsp.Success)
+		assert.NotContains(t, RegisteredDataTypes(), "user")
+	})
+
+	t.Run("records types in generation mode", func(t *testing.T) {
+		defer func(v bool) { RecordDataTypes = v }(RecordDataTypes)
+		RecordDataTypes = true
+
+		Typed(http.StatusCreated, "", "user.list", []user{{ID: 1}})
+		assert.Equal(t, reflect.TypeOf([]user{}), RegisteredDataTypes()["user.list"])
+	})
+}
@@ -0,0 +1,31 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// UploadStatus creates a response with (HTTP 200) code reporting the progress of
+// a resumable (tus-style) upload. Data carries `offset`, `total`, `uploadId` and
+// `complete`, and Write emits the `Upload-Offset` header, plus `Upload-Length` when
+// total is known (greater than 0).
+func UploadStatus(offset, total int64, uploadID string) *APIResponse {
+	complete := total > 0 && offset >= total
+
+	msg := "Upload in progress"
+	if complete {
+		msg = "Upload complete"
+	}
+
+	rsp := Success(http.StatusOK, msg, map[string]any{
+		"offset":   offset,
+		"total":    total,
+		"uploadId": uploadID,
+		"complete": complete,
+	})
+	rsp.SetHeader("Upload-Offset", strconv.FormatInt(offset, 10))
+	if total > 0 {
+		rsp.SetHeader("Upload-Length", strconv.FormatInt(total, 10))
+	}
+	return rsp
+}
@@ -0,0 +1,32 @@
+package response
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadStatus(t *testing.T) {
+	t.Run("in progress", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		assert.NoError(t, UploadStatus(512, 2048, "up_1").Write(rec))
+
+		assert.Equal(t, "512", rec.Header().Get("Upload-Offset"))
+		assert.Equal(t, "2048", rec.Header().Get("Upload-Length"))
+		assert.JSONEq(t, `{"version":"1","success":true,"message":"Upload in progress",
+			"data":{"offset":512,"total":2048,"uploadId":"up_1","complete":false}}`, rec.Body.String())
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		got := UploadStatus(2048, 2048, "up_1")
+		assert.Equal(t, "Upload complete", got.Message)
+		assert.Equal(t, true, got.Data.(map[string]any)["complete"])
+	})
+
+	t.Run("unknown length", func(t *testing.T) {
+		got := UploadStatus(100, 0, "up_2")
+		assert.Equal(t, false, got.Data.(map[string]any)["complete"])
+		assert.Empty(t, got.Headers.Get("Upload-Length"))
+	})
+}
@@ -50,3 +50,38 @@ func IsJsonErrorGetDetails(err error) (ok bool, e error) {
 	}
 	return false, nil
 }
+
+// ErrorDetails converts an error into a JSON-friendly map for use in Details, since
+// a raw error value marshals to an empty object.
+//
+// The map always holds the error `message`. For JSON errors the message is the one
+// from IsJsonErrorGetDetails, and structured fields are added: `offset` for syntax
+// errors, and `field`, `offset`, `expected` and `got` for type mismatches.
+// A nil error returns nil.
+func ErrorDetails(err error) map[string]any {
+	if err == nil {
+		return nil
+	}
+
+	details := map[string]any{"message": err.Error()}
+
+	isJsonErr, jsonErr := IsJsonErrorGetDetails(err)
+	if !isJsonErr {
+		return details
+	}
+	details["message"] = jsonErr.Error()
+
+	var syntaxError *json.SyntaxError
+	var unmarshalTypeError *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxError):
+		details["offset"] = syntaxError.Offset
+	case errors.As(err, &unmarshalTypeError):
+		details["field"] = unmarshalTypeError.Field
+		details["offset"] = unmarshalTypeError.Offset
+		details["expected"] = unmarshalTypeError.Type.String()
+		details["got"] = unmarshalTypeError.Value
+	}
+	return details
+}
@@ -56,3 +56,34 @@ func TestIsJsonErrorGetDetails(t *testing.T) {
 		assert.Equal(t, false, ok)
 	})
 }
+
+func TestErrorDetails(t *testing.T) {
+	t.Run("plain error", func(t *testing.T) {
+		assert.Equal(t, map[string]any{"message": "boom"}, ErrorDetails(errors.New("boom")))
+	})
+
+	t.Run("json syntax error", func(t *testing.T) {
+		var v map[string]any
+		err := json.Unmarshal([]byte(`{"a":}`), &v)
+
+		got := ErrorDetails(err)
+		assert.Equal(t, "body contains badly-formed JSON (at character 6)", got["message"])
+		assert.Equal(t, int64(6), got["offset"])
+	})
+
+	t.Run("json unmarshal type error", func(t *testing.T) {
+		var v struct {
+			Age int `json:"age"`
+		}
+		err := json.Unmarshal([]byte(`{"age":"ten"}`), &v)
+
+		got := ErrorDetails(err)
+		assert.Equal(t, "age", got["field"])
+		assert.Equal(t, "int", got["expected"])
+		assert.Equal(t, "string", got["got"])
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		assert.Nil(t, ErrorDetails(nil))
+	})
+}
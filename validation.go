@@ -0,0 +1,238 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldError describes why a single field of a request failed validation.
+//
+// Field: Name of the offending field as the client sent it.
+// Code: Stable machine-readable code (e.g. "required", "too_long") clients can localize on.
+// Message: Human-readable fallback message.
+// Value: (Optional) The rejected value. Leave it unset for sensitive fields.
+// Pointer: (Optional) JSON Pointer (RFC 6901) to the field in the request body, e.g. "/items/2/price".
+// Path: (Optional) Location of the field in the response Data, GraphQL style, e.g. ["user", "friends", 1].
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Value   any    `json:"value,omitempty"`
+	Pointer string `json:"pointer,omitempty"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// NewFieldError builds a FieldError without a rejected value, so nothing
+// sensitive is echoed back unless WithValue is called explicitly.
+func NewFieldError(field, code, message string) FieldError {
+	return FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// WithValue returns a copy of the field error carrying the rejected value.
+func (f FieldError) WithValue(value any) FieldError {
+	f.Value = value
+	return f
+}
+
+// WithPointer returns a copy of the field error locating it with a JSON Pointer.
+func (f FieldError) WithPointer(pointer string) FieldError {
+	f.Pointer = pointer
+	return f
+}
+
+// WithPath returns a copy of the field error locating it in the response Data by
+// object keys and array indexes, see GraphQLLike.
+func (f FieldError) WithPath(path ...any) FieldError {
+	f.Path = path
+	return f
+}
+
+// MaxFieldErrors bounds the number of field errors a ValidationFailed response
+// carries, so large forms don't overwhelm the client. Extra errors are dropped and
+// Meta notes `"truncated": true` with the `totalErrors` count. 0 means unlimited.
+var MaxFieldErrors int
+
+// Creates a response with (HTTP 422) code carrying field-level errors, at most
+// MaxFieldErrors of them.
+func ValidationFailed(msg string, errorCode string, fieldErrors ...FieldError) *APIResponse {
+	if msg == "" {
+		msg = "Request failed validation"
+	}
+	rsp := Error(http.StatusUnprocessableEntity, msg, errorCode)
+	rsp.Errors = fieldErrors
+
+	if MaxFieldErrors > 0 && len(fieldErrors) > MaxFieldErrors {
+		rsp.Errors = fieldErrors[:MaxFieldErrors:MaxFieldErrors]
+		rsp.withMeta("truncated", true).withMeta("totalErrors", len(fieldErrors))
+	}
+	return rsp
+}
+
+// FromOzzoErrors converts the errors of github.com/go-ozzo/ozzo-validation into a
+// ValidationFailed response. validation.Errors (a map[string]error, possibly nested
+// for structs and slices) is detected structurally anywhere in err's chain, so the
+// package doesn't depend on the library. Nested keys are joined with dots, e.g.
+// "address.city". Each entry's code is taken from its `Code() string` method when
+// present, "invalid" otherwise.
+//
+// Unrecognized errors fall through to BadRequest; a nil error returns nil.
+func FromOzzoErrors(err error) *APIResponse {
+	if err == nil {
+		return nil
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if fieldErrors, ok := ozzoFieldErrors(e, ""); ok {
+			return ValidationFailed("", "VALIDATION_FAILED", fieldErrors...)
+		}
+	}
+	return BadRequest("", "BAD_REQUEST")
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ozzoFieldErrors flattens a map[string]error shaped error into field errors.
+func ozzoFieldErrors(err error, prefix string) ([]FieldError, bool) {
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String || v.Type().Elem() != errorType {
+		return nil, false
+	}
+
+	keys := v.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+
+	var fieldErrors []FieldError
+	for _, key := range keys {
+		fieldErr, _ := v.MapIndex(key).Interface().(error)
+		if fieldErr == nil {
+			continue
+		}
+
+		field := prefix + key.String()
+		if nested, ok := ozzoFieldErrors(fieldErr, field+"."); ok {
+			fieldErrors = append(fieldErrors, nested...)
+			continue
+		}
+
+		code := "invalid"
+		if coder, ok := fieldErr.(interface{ Code() string }); ok && coder.Code() != "" {
+			code = coder.Code()
+		}
+		fieldErrors = append(fieldErrors, NewFieldError(field, code, fieldErr.Error()))
+	}
+	return fieldErrors, true
+}
+
+// playgroundFieldError is the subset of validator.FieldError from
+// github.com/go-playground/validator used to build field errors.
+type playgroundFieldError interface {
+	Namespace() string
+	Field() string
+	Tag() string
+	Error() string
+}
+
+// FromValidationErrors converts validator.ValidationErrors from
+// github.com/go-playground/validator into a ValidationFailed response. The errors are
+// detected structurally anywhere in err's chain, so the package doesn't depend on the
+// library. Each field error uses the validation tag as its code and carries a JSON
+// Pointer derived from its namespace, e.g. `User.items[2].unit_price` becomes
+// `/items/2/unit_price`.
+//
+// Field and pointer names are taken from the validator as-is. Register a tag name
+// function returning the JSON names so they match the request body:
+//
+//	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+//		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+//		if name == "-" {
+//			return ""
+//		}
+//		return name
+//	})
+//
+// Unrecognized errors fall through to BadRequest; a nil error returns nil.
+func FromValidationErrors(err error) *APIResponse {
+	if err == nil {
+		return nil
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		v := reflect.ValueOf(e)
+		if v.Kind() != reflect.Slice {
+			continue
+		}
+
+		var fieldErrors []FieldError
+		for i := 0; i < v.Len(); i++ {
+			fe, ok := v.Index(i).Interface().(playgroundFieldError)
+			if !ok {
+				fieldErrors = nil
+				break
+			}
+			fieldErrors = append(fieldErrors,
+				NewFieldError(fe.Field(), fe.Tag(), fe.Error()).WithPointer(namespaceToPointer(fe.Namespace())),
+			)
+		}
+		if fieldErrors != nil {
+			return ValidationFailed("", "VALIDATION_FAILED", fieldErrors...)
+		}
+	}
+	return BadRequest("", "BAD_REQUEST")
+}
+
+// namespaceToPointer converts a validator namespace such as `User.items[2].price` or
+// `User.attrs[color]` into a JSON Pointer (`/items/2/price`, `/attrs/color`). The
+// leading struct name is dropped and field names are kept as the validator named them.
+func namespaceToPointer(namespace string) string {
+	var tokens []string
+	for i, part := range strings.Split(namespace, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if i > 0 && name != "" {
+			tokens = append(tokens, name)
+		}
+		for rest != "" {
+			var key string
+			key, rest, _ = strings.Cut(rest, "]")
+			tokens = append(tokens, key)
+			rest = strings.TrimPrefix(rest, "[")
+		}
+	}
+
+	var sb strings.Builder
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(escaper.Replace(token))
+	}
+	return sb.String()
+}
+
+// ErrorNode is one node of a tree of grouped errors, e.g. a batch item and the
+// errors of its sub-entities.
+//
+// Path: Location of the node, e.g. "orders[3]" or "orders[3].lines[0]".
+// Message: (Optional) Error at this node.
+// Children: (Optional) Nested errors, in order.
+type ErrorNode struct {
+	Path     string      `json:"path"`
+	Message  string      `json:"message,omitempty"`
+	Children []ErrorNode `json:"children,omitempty"`
+}
+
+// GroupedErrors creates a response with (HTTP 422) code carrying a tree of errors
+// at the top-level `errors` key, in place of a flat list of field errors, for clients
+// rendering nested failures as a collapsible tree. The key holds a one-element array
+// with root, so it stays an array like for other responses. Child order is preserved
+// and the tree may be arbitrarily deep.
+func GroupedErrors(root ErrorNode) *APIResponse {
+	rsp := ValidationFailed("", "VALIDATION_FAILED")
+	rsp.errorTree = &root
+	return rsp
+}
@@ -0,0 +1,211 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFieldError(t *testing.T) {
+	t.Run("value is omitted unless set", func(t *testing.T) {
+		fe := NewFieldError("password", "too_short", "password must be at least 8 characters")
+		rsp := ValidationFailed("", "VALIDATION", fe)
+
+		v, err := rsp.ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"version": "1",
+			"success": false,
+			"message": "Request failed validation",
+			"errorCode": "VALIDATION",
+			"errors": [{"field": "password", "code": "too_short", "message": "password must be at least 8 characters"}]
+		}`, v)
+	})
+
+	t.Run("round trips with a rejected value", func(t *testing.T) {
+		fe := NewFieldError("name", "too_long", "name is too long").WithValue("abcdef")
+		rsp := ValidationFailed("invalid input", "", fe)
+		assert.Equal(t, http.StatusUnprocessableEntity, rsp.StatusCode)
+
+		v, err := rsp.ToJson()
+		assert.NoError(t, err)
+
+		got, err := FromJsonToAPIResponse([]byte(v))
+		assert.NoError(t, err)
+		assert.Equal(t, []FieldError{fe}, got.Errors)
+	})
+}
+
+func TestMaxFieldErrors(t *testing.T) {
+	defer func(v int) { MaxFieldErrors = v }(MaxFieldErrors)
+	MaxFieldErrors = 2
+
+	errs := []FieldError{
+		NewFieldError("a", "required", ""),
+		NewFieldError("b", "required", ""),
+		NewFieldError("c", "required", ""),
+	}
+
+	got := ValidationFailed("", "", errs...)
+	assert.Equal(t, errs[:2], got.Errors)
+	assert.Equal(t, map[string]any{"truncated": true, "totalErrors": 3}, got.Meta)
+
+	got = ValidationFailed("", "", errs[:2]...)
+	assert.Len(t, got.Errors, 2)
+	assert.Nil(t, got.Meta)
+}
+
+// ozzoErrors mirrors validation.Errors from github.com/go-ozzo/ozzo-validation.
+type ozzoErrors map[string]error
+
+func (e ozzoErrors) Error() string { return "validation failed" }
+
+type ozzoError struct{ code, msg string }
+
+func (e ozzoError) Error() string { return e.msg }
+func (e ozzoError) Code() string  { return e.code }
+
+func TestFromOzzoErrors(t *testing.T) {
+	t.Run("flattens nested errors", func(t *testing.T) {
+		err := fmt.Errorf("create user: %w", ozzoErrors{
+			"name":  ozzoError{code: "validation_required", msg: "cannot be blank"},
+			"email": errors.New("must be a valid email address"),
+			"address": ozzoErrors{
+				"city": ozzoError{code: "validation_length_out_of_range", msg: "the length must be between 2 and 50"},
+			},
+			"nickname": nil,
+		})
+
+		got := FromOzzoErrors(err)
+		assert.Equal(t, http.StatusUnprocessableEntity, got.StatusCode)
+		assert.Equal(t, []FieldError{
+			NewFieldError("address.city", "validation_length_out_of_range", "the length must be between 2 and 50"),
+			NewFieldError("email", "invalid", "must be a valid email address"),
+			NewFieldError("name", "validation_required", "cannot be blank"),
+		}, got.Errors)
+	})
+
+	t.Run("unrecognized error", func(t *testing.T) {
+		got := FromOzzoErrors(errors.New("boom"))
+		assert.Equal(t, http.StatusBadRequest, got.StatusCode)
+		assert.Empty(t, got.Errors)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		assert.Nil(t, FromOzzoErrors(nil))
+	})
+}
+
+// playgroundError mirrors validator.FieldError from github.com/go-playground/validator.
+type playgroundError struct{ namespace, field, tag string }
+
+func (e playgroundError) Namespace() string { return e.namespace }
+func (e playgroundError) Field() string     { return e.field }
+func (e playgroundError) Tag() string       { return e.tag }
+func (e playgroundError) Error() string {
+	return "Field validation for '" + e.field + "' failed on the '" + e.tag + "' tag"
+}
+
+// playgroundErrors mirrors validator.ValidationErrors.
+type playgroundErrors []playgroundFieldError
+
+func (e playgroundErrors) Error() string { return "validation failed" }
+
+func TestFromValidationErrors(t *testing.T) {
+	t.Run("field errors with pointers", func(t *testing.T) {
+		err := fmt.Errorf("create order: %w", playgroundErrors{
+			playgroundError{namespace: "Order.items[2].unit_price", field: "unit_price", tag: "gt"},
+			playgroundError{namespace: "Order.email", field: "email", tag: "email"},
+		})
+
+		got := FromValidationErrors(err)
+		assert.Equal(t, http.StatusUnprocessableEntity, got.StatusCode)
+		assert.Equal(t, []FieldError{
+			NewFieldError("unit_price", "gt", "Field validation for 'unit_price' failed on the 'gt' tag").WithPointer("/items/2/unit_price"),
+			NewFieldError("email", "email", "Field validation for 'email' failed on the 'email' tag").WithPointer("/email"),
+		}, got.Errors)
+	})
+
+	t.Run("unrecognized error", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, FromValidationErrors(errors.New("boom")).StatusCode)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		assert.Nil(t, FromValidationErrors(nil))
+	})
+}
+
+func TestNamespaceToPointer(t *testing.T) {
+	tests := map[string]string{
+		"User.name":              "/name",
+		"User.items[2].price":    "/items/2/price",
+		"User.attrs[color]":      "/attrs/color",
+		"User.matrix[1][0]":      "/matrix/1/0",
+		"User.paths[a/b~c].name": "/paths/a~1b~0c/name",
+		"User.ID":                "/ID",
+		"User.createdAt":         "/createdAt",
+	}
+	for namespace, want := range tests {
+		assert.Equal(t, want, namespaceToPointer(namespace), namespace)
+	}
+}
+
+func TestGroupedErrors(t *testing.T) {
+	root := ErrorNode{
+		Path: "orders",
+		Children: []ErrorNode{
+			{Path: "orders[1]", Message: "order is invalid", Children: []ErrorNode{
+				{Path: "orders[1].lines[0]", Children: []ErrorNode{
+					{Path: "orders[1].lines[0].qty", Message: "must be positive"},
+				}},
+			}},
+			{Path: "orders[0]", Message: "duplicate order"},
+		},
+	}
+
+	rsp := GroupedErrors(root)
+	assert.Equal(t, http.StatusUnprocessableEntity, rsp.StatusCode)
+
+	v, err := rsp.ToJson()
+	assert.NoError(t, err)
+	assert.Contains(t, v, `"errors":[{"path":"orders","children":[{"path":"orders[1]","message":"order is invalid","children":[{"path":"orders[1].lines[0]","children":[{"path":"orders[1].lines[0].qty","message":"must be positive"}]}]},{"path":"orders[0]","message":"duplicate order"}]}]`)
+	assert.NotContains(t, v, `"details"`)
+
+	t.Run("renamed errors key", func(t *testing.T) {
+		defer func(v EnvelopeFieldNames) { FieldNames = v }(FieldNames)
+		FieldNames = EnvelopeFieldNames{Errors: "problems"}
+
+		v, err := GroupedErrors(ErrorNode{Path: "orders"}).ToJson()
+		assert.NoError(t, err)
+		assert.Contains(t, v, `"problems":[{"path":"orders"}]`)
+
+		decoded, err := FromJsonToAPIResponse([]byte(v))
+		assert.NoError(t, err)
+		assert.Equal(t, &ErrorNode{Path: "orders"}, decoded.errorTree)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		decoded, err := FromJsonToAPIResponse([]byte(v))
+		assert.NoError(t, err)
+		assert.Equal(t, &root, decoded.errorTree)
+		assert.Empty(t, decoded.Errors)
+		assert.Equal(t, "VALIDATION_FAILED", *decoded.ErrorCode)
+
+		again, err := decoded.ToJson()
+		assert.NoError(t, err)
+		assert.JSONEq(t, v, again)
+	})
+
+	t.Run("field errors still decode", func(t *testing.T) {
+		v, err := ValidationFailed("", "", NewFieldError("email", "required", "is required").WithPath("user", 1)).ToJson()
+		assert.NoError(t, err)
+
+		decoded, err := FromJsonToAPIResponse([]byte(v))
+		assert.NoError(t, err)
+		assert.Nil(t, decoded.errorTree)
+		assert.Equal(t, []any{"user", float64(1)}, decoded.Errors[0].Path)
+	})
+}
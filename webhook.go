@@ -0,0 +1,79 @@
+package response
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Errors returned by VerifyWebhook.
+var (
+	ErrWebhookSignature = errors.New("response: invalid webhook signature")
+	ErrWebhookExpired   = errors.New("response: webhook timestamp outside tolerance")
+)
+
+// WebhookPayload prepares the response for delivery as an outbound webhook. It
+// returns the JSON body together with the headers to send with it:
+//
+//	X-Event-Id:  a random ID receivers can use to drop duplicate deliveries
+//	X-Timestamp: the Unix time of signing, in seconds
+//	X-Signature: "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret
+//
+// Signing the timestamp with the body stops a captured delivery from being replayed
+// later. Receivers check both with VerifyWebhook.
+func (r *APIResponse) WebhookPayload(secret []byte) (body []byte, headers http.Header, err error) {
+	body, err = json.Marshal(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	headers = http.Header{}
+	headers.Set("Content-Type", "application/json")
+	headers.Set("X-Event-Id", hex.EncodeToString(id))
+	headers.Set("X-Timestamp", timestamp)
+	headers.Set("X-Signature", webhookSignature(secret, timestamp, body))
+	return body, headers, nil
+}
+
+// VerifyWebhook checks a delivery produced by WebhookPayload. It returns
+// ErrWebhookSignature when the X-Signature header doesn't match body, and
+// ErrWebhookExpired when X-Timestamp is further than tolerance from now.
+// A tolerance of 0 skips the timestamp check.
+func VerifyWebhook(secret, body []byte, headers http.Header, tolerance time.Duration) error {
+	timestamp := headers.Get("X-Timestamp")
+	want := webhookSignature(secret, timestamp, body)
+	if !hmac.Equal([]byte(want), []byte(headers.Get("X-Signature"))) {
+		return ErrWebhookSignature
+	}
+
+	if tolerance > 0 {
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return ErrWebhookSignature
+		}
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrWebhookExpired
+		}
+	}
+	return nil
+}
+
+func webhookSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
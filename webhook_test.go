@@ -0,0 +1,38 @@
+package response
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_WebhookPayload(t *testing.T) {
+	secret := []byte("s3cret")
+
+	body, headers, err := OK("user.created", map[string]any{"id": 1}).WebhookPayload(secret)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version":"1","success":true,"message":"user.created","data":{"id":1}}`, string(body))
+	assert.Len(t, headers.Get("X-Event-Id"), 32)
+	assert.NotEmpty(t, headers.Get("X-Timestamp"))
+
+	t.Run("valid delivery", func(t *testing.T) {
+		assert.NoError(t, VerifyWebhook(secret, body, headers, time.Minute))
+	})
+
+	t.Run("tampered body or wrong secret", func(t *testing.T) {
+		assert.ErrorIs(t, VerifyWebhook(secret, append(body, ' '), headers, 0), ErrWebhookSignature)
+		assert.ErrorIs(t, VerifyWebhook([]byte("other"), body, headers, 0), ErrWebhookSignature)
+	})
+
+	t.Run("stale timestamp", func(t *testing.T) {
+		stale := headers.Clone()
+		timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+		stale.Set("X-Timestamp", timestamp)
+		stale.Set("X-Signature", webhookSignature(secret, timestamp, body))
+
+		assert.ErrorIs(t, VerifyWebhook(secret, body, stale, time.Minute), ErrWebhookExpired)
+		assert.NoError(t, VerifyWebhook(secret, body, stale, 0))
+	})
+}
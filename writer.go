@@ -0,0 +1,319 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// OnResponse, when set, is called exactly once by Write after each response has
+// been written, e.g. to count responses by status and error code. It must be safe
+// for concurrent use.
+var OnResponse func(*APIResponse)
+
+// Transformers are applied in order by Write before a response is sent, e.g. to
+// inject a correlation header or scrub fields. A transformer may modify the response
+// or return a different one to replace it entirely; returning nil short-circuits the
+// pipeline, skipping the remaining transformers.
+var Transformers []func(*APIResponse) *APIResponse
+
+// WriteJSON writes v to w as JSON with the given status code. It is the low-level
+// counterpart of Write, useful for endpoints that don't need the envelope (e.g.
+// health checks or metrics). The encode error is returned for logging; by then the
+// status has already been sent.
+func WriteJSON(w http.ResponseWriter, statusCode int, v any) error {
+	if alreadySent(w, statusCode) {
+		return ErrAlreadySent
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	err := json.NewEncoder(w).Encode(v)
+	sealResponse(w)
+	return err
+}
+
+// MaxResponseBytes bounds the size of the JSON body sent by Write. A larger body is
+// replaced by an InternalServerError with code RESPONSE_TOO_LARGE, and logged. This
+// guards clients and egress against unexpectedly huge results. 0 means unlimited.
+var MaxResponseBytes int64
+
+// Write sends the response to w as JSON. StatusCode is used as the HTTP status
+// (defaulting to 200 when unset) and any Headers set on the response are emitted
+// before the body. Statuses that forbid a body (1xx, 204, 304) are sent with headers
+// only. Transformers run first and OnResponse is called last.
+//
+// When Data holds a value JSON can't encode (e.g. a channel, a func or NaN), the
+// offending type is logged and an InternalServerError with code SERIALIZATION_ERROR
+// is sent instead, so the client still gets a well-formed response.
+func (r *APIResponse) Write(w http.ResponseWriter) error {
+	return r.transform().write(w)
+}
+
+// write sends the response to w once Transformers have run, see Write.
+func (r *APIResponse) write(w http.ResponseWriter) error {
+	if r.filePath != "" {
+		return r.writeFile(w)
+	}
+	if !bodyAllowed(r.httpStatus()) {
+		return r.send(w, "", nil)
+	}
+
+	r, body, err := r.jsonBody()
+	if err != nil {
+		return err
+	}
+	return r.send(w, "application/json", body)
+}
+
+// jsonBody encodes the response as a newline-terminated JSON body. When Data can't
+// be serialized, or the body exceeds MaxResponseBytes, the failure is logged and an
+// InternalServerError is encoded instead; the response actually encoded is returned
+// with its body.
+func (r *APIResponse) jsonBody() (*APIResponse, []byte, error) {
+	body, n, err := r.MarshalWithLength()
+	if offending, ok := unsupportedJSON(err); ok {
+		slog.Error("response error: data cannot be serialized",
+			"offending", offending, "statusCode", r.httpStatus())
+
+		r = InternalServerError("", "SERIALIZATION_ERROR")
+		body, n, err = r.MarshalWithLength()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if MaxResponseBytes > 0 && int64(n) > MaxResponseBytes {
+		slog.Error("response error: body exceeds MaxResponseBytes",
+			"size", n, "limit", MaxResponseBytes, "statusCode", r.httpStatus())
+
+		r = InternalServerError("", "RESPONSE_TOO_LARGE")
+		if body, err = json.Marshal(r); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return r, append(body, '\n'), nil
+}
+
+// unsupportedJSON reports whether err comes from a type or value JSON can't encode,
+// and describes the offending type or value.
+func unsupportedJSON(err error) (string, bool) {
+	var unsupportedType *json.UnsupportedTypeError
+	if errors.As(err, &unsupportedType) {
+		return unsupportedType.Type.String(), true
+	}
+
+	var unsupportedValue *json.UnsupportedValueError
+	if errors.As(err, &unsupportedValue) {
+		return unsupportedValue.Str, true
+	}
+	return "", false
+}
+
+// MarshalWithLength encodes the response as JSON and returns the body with its
+// length in bytes, e.g. to set Content-Length before the status is sent.
+func (r *APIResponse) MarshalWithLength() ([]byte, int, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return b, len(b), nil
+}
+
+// send writes the response headers, status and an already encoded body to w, then
+// calls OnResponse. The body is sent with its Content-Length, so small responses
+// avoid chunked encoding. An empty contentType sends headers only. Nothing is sent
+// to a GuardWriter that already sent a response.
+func (r *APIResponse) send(w http.ResponseWriter, contentType string, body []byte) error {
+	if r.alreadySent(w) {
+		return ErrAlreadySent
+	}
+
+	r.writeHeaders(w)
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	w.WriteHeader(r.httpStatus())
+
+	var err error
+	if contentType != "" {
+		_, err = w.Write(body)
+	}
+	sealResponse(w)
+
+	if OnResponse != nil {
+		OnResponse(r)
+	}
+	return err
+}
+
+// transform runs the response through Transformers.
+func (r *APIResponse) transform() *APIResponse {
+	for _, fn := range Transformers {
+		next := fn(r)
+		if next == nil {
+			break
+		}
+		r = next
+	}
+	return r
+}
+
+// bodyAllowed reports whether a response with statusCode may carry a body.
+func bodyAllowed(statusCode int) bool {
+	switch {
+	case statusCode < http.StatusOK,
+		statusCode == http.StatusNoContent,
+		statusCode == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// writeHeaders copies the response Headers to w, replacing existing values, and
+// adds a `Link: <HelpURL>; rel="help"` header when HelpURL is set. Error responses
+// are always sent with `Cache-Control: no-store`, replacing any caching policy, and
+// `no-transform` is added for responses opted out of compression.
+func (r *APIResponse) writeHeaders(w http.ResponseWriter) {
+	for key, values := range r.Headers {
+		w.Header()[key] = append([]string(nil), values...)
+	}
+	if r.httpStatus() >= http.StatusBadRequest {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	if r.HelpURL != "" {
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="help"`, r.HelpURL))
+	}
+	if r.noCompress {
+		if cc := w.Header().Get("Cache-Control"); cc != "" {
+			w.Header().Set("Cache-Control", cc+", no-transform")
+		} else {
+			w.Header().Set("Cache-Control", "no-transform")
+		}
+	}
+}
+
+// NoCompress opts the response out of compression, e.g. when Data carries
+// already-compressed binary or the payload is tiny. Compressing writers and
+// middleware should check Compressible before encoding the body; Write also adds
+// `no-transform` to Cache-Control so proxies leave the body as is.
+func (r *APIResponse) NoCompress() *APIResponse {
+	r.noCompress = true
+	return r
+}
+
+// Compressible reports whether the response may be compressed, see NoCompress.
+func (r *APIResponse) Compressible() bool {
+	return !r.noCompress
+}
+
+// httpStatus returns StatusCode, defaulting to 200 when unset.
+func (r *APIResponse) httpStatus() int {
+	if r.StatusCode == 0 {
+		return http.StatusOK
+	}
+	return r.StatusCode
+}
+
+// WriteNDJSON streams items to w as newline-delimited JSON (`application/x-ndjson`),
+// one item per line, flushing after each line when w supports it. It returns once
+// items is closed, or with the first encode/write error; the caller should then stop
+// its producer. To lead with envelope metadata, send an *APIResponse as the first item.
+func WriteNDJSON(w http.ResponseWriter, items <-chan any) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	for item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
+
+// ErrNoResult is returned by WriteWithHeartbeat when the result channel is closed
+// without delivering a response, or delivers a nil one.
+var ErrNoResult = errors.New("response: result channel closed without a response")
+
+// defaultHeartbeatInterval is used by WriteWithHeartbeat for a non-positive interval,
+// well below the common 30-60s proxy idle timeouts.
+const defaultHeartbeatInterval = 15 * time.Second
+
+// WriteWithHeartbeat keeps a slow request alive by writing a newline every interval
+// until the final response arrives on result, then writes it as JSON. Newlines are
+// valid leading JSON whitespace, so the body still parses, and they stop proxies
+// from timing out the connection.
+//
+// The status line can only be sent once: if the result arrives before the first
+// heartbeat it is written normally with its StatusCode; after that the status has
+// already been committed as 200 and the outcome is only reflected in the body. That
+// body is the JSON envelope even for statuses that normally have none (e.g. 204),
+// with the same SERIALIZATION_ERROR and RESPONSE_TOO_LARGE fallbacks as Write. A
+// file response can't follow heartbeats and is replaced by an InternalServerError
+// with code UNSUPPORTED_RESPONSE.
+//
+// An interval of 0 or less uses defaultHeartbeatInterval.
+func WriteWithHeartbeat(w http.ResponseWriter, result <-chan *APIResponse, interval time.Duration) error {
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	flusher, _ := w.(http.Flusher)
+	started := false
+
+	for {
+		select {
+		case rsp, ok := <-result:
+			if !ok || rsp == nil {
+				return ErrNoResult
+			}
+			if !started {
+				return rsp.Write(w)
+			}
+
+			rsp = rsp.transform()
+			if rsp.filePath != "" {
+				slog.Error("response error: cant send a file after heartbeats", "statusCode", rsp.httpStatus())
+				rsp = InternalServerError("", "UNSUPPORTED_RESPONSE")
+			}
+			rsp, body, err := rsp.jsonBody()
+			if err != nil {
+				return err
+			}
+
+			_, err = w.Write(body)
+			sealResponse(w)
+			if OnResponse != nil {
+				OnResponse(rsp)
+			}
+			return err
+
+		case <-ticker.C:
+			if !started {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				started = true
+			}
+			if _, err := w.Write([]byte("\n")); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
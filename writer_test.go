@@ -0,0 +1,274 @@
+package response
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIResponse_Write(t *testing.T) {
+	t.Run("writes status headers and json body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rsp := NotFound("missing", "NF_001").SetHeader("X-Trace", "abc")
+
+		err := rsp.Write(rec)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "abc", rec.Header().Get("X-Trace"))
+		assert.JSONEq(t, `{"version":"1","success":false,"message":"missing","errorCode":"NF_001"}`, rec.Body.String())
+		assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get("Content-Length"))
+	})
+
+	t.Run("defaults to 200 when status code is unset", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		err := (&APIResponse{Success: true, Message: "ok"}).Write(rec)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestAPIResponse_MarshalWithLength(t *testing.T) {
+	b, n, err := OK("", nil).MarshalWithLength()
+	assert.NoError(t, err)
+	assert.Equal(t, len(b), n)
+	assert.JSONEq(t, `{"version":"1","success":true,"message":"Request was successful"}`, string(b))
+
+	_, _, err = OK("", make(chan int)).MarshalWithLength()
+	assert.Error(t, err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	err := WriteJSON(rec, http.StatusOK, map[string]string{"status": "up"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"status":"up"}`, rec.Body.String())
+
+	t.Run("returns the encode error", func(t *testing.T) {
+		err := WriteJSON(httptest.NewRecorder(), http.StatusOK, make(chan int))
+		assert.Error(t, err)
+	})
+}
+
+func TestOnResponse(t *testing.T) {
+	defer func(fn func(*APIResponse)) { OnResponse = fn }(OnResponse)
+
+	var got []*APIResponse
+	OnResponse = func(r *APIResponse) { got = append(got, r) }
+
+	rsp := Forbidden("", "NO_ACCESS")
+	assert.NoError(t, rsp.Write(httptest.NewRecorder()))
+	assert.Equal(t, []*APIResponse{rsp}, got)
+
+	OnResponse = nil
+	assert.NoError(t, rsp.Write(httptest.NewRecorder()))
+}
+
+func TestTransformers(t *testing.T) {
+	defer func(v []func(*APIResponse) *APIResponse) { Transformers = v }(Transformers)
+
+	t.Run("applied in order and can replace the response", func(t *testing.T) {
+		Transformers = []func(*APIResponse) *APIResponse{
+			func(r *APIResponse) *APIResponse { return r.SetHeader("X-Correlation-ID", "c-1") },
+			func(r *APIResponse) *APIResponse {
+				if r.StatusCode >= http.StatusInternalServerError {
+					return InternalServerError("", "INTERNAL").SetHeader("X-Correlation-ID", r.Headers.Get("X-Correlation-ID"))
+				}
+				return r
+			},
+		}
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, InternalServerError("db password leaked", "DB").Write(rec))
+		assert.Equal(t, "c-1", rec.Header().Get("X-Correlation-ID"))
+		assert.JSONEq(t, `{"version":"1","success":false,"message":"Something went wrong on our end.","errorCode":"INTERNAL"}`, rec.Body.String())
+	})
+
+	t.Run("nil short-circuits the pipeline", func(t *testing.T) {
+		called := false
+		Transformers = []func(*APIResponse) *APIResponse{
+			func(r *APIResponse) *APIResponse { return nil },
+			func(r *APIResponse) *APIResponse { called = true; return r },
+		}
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, OK("kept", nil).Write(rec))
+		assert.False(t, called)
+		assert.Contains(t, rec.Body.String(), `"kept"`)
+	})
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	items := make(chan any, 3)
+	items <- List("export", nil, map[string]any{"total": 2})
+	items <- map[string]int{"id": 1}
+	items <- map[string]int{"id": 2}
+	close(items)
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, WriteNDJSON(rec, items))
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t,
+		`{"version":"1","success":true,"message":"export","meta":{"total":2}}`+"\n"+`{"id":1}`+"\n"+`{"id":2}`+"\n",
+		rec.Body.String(),
+	)
+
+	t.Run("stops at the first encode error", func(t *testing.T) {
+		items := make(chan any, 2)
+		items <- make(chan int)
+		items <- 1
+		close(items)
+
+		rec := httptest.NewRecorder()
+		assert.Error(t, WriteNDJSON(rec, items))
+		assert.Empty(t, rec.Body.String())
+	})
+}
+
+func TestWriteWithHeartbeat(t *testing.T) {
+	t.Run("result before first heartbeat keeps its status", func(t *testing.T) {
+		result := make(chan *APIResponse, 1)
+		result <- NotFound("", "")
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, WriteWithHeartbeat(rec, result, time.Hour))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("heartbeats precede a parsable body", func(t *testing.T) {
+		result := make(chan *APIResponse)
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			result <- OK("slow but done", nil)
+		}()
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, WriteWithHeartbeat(rec, result, 5*time.Millisecond))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "\n"))
+
+		got, err := FromJsonToAPIResponse(rec.Body.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, "slow but done", got.Message)
+	})
+
+	t.Run("unserializable result after heartbeats", func(t *testing.T) {
+		defer func(fn func(*APIResponse)) { OnResponse = fn }(OnResponse)
+		var sent []*APIResponse
+		OnResponse = func(r *APIResponse) { sent = append(sent, r) }
+
+		result := make(chan *APIResponse)
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			result <- OK("", make(chan int))
+		}()
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, WriteWithHeartbeat(rec, result, 5*time.Millisecond))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		got, err := FromJsonToAPIResponse(rec.Body.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, "SERIALIZATION_ERROR", *got.ErrorCode)
+		assert.Len(t, sent, 1)
+		assert.Equal(t, http.StatusInternalServerError, sent[0].StatusCode)
+	})
+
+	t.Run("closed channel", func(t *testing.T) {
+		result := make(chan *APIResponse)
+		close(result)
+		assert.ErrorIs(t, WriteWithHeartbeat(httptest.NewRecorder(), result, time.Hour), ErrNoResult)
+	})
+
+	t.Run("nil result", func(t *testing.T) {
+		result := make(chan *APIResponse, 1)
+		result <- nil
+		assert.ErrorIs(t, WriteWithHeartbeat(httptest.NewRecorder(), result, time.Hour), ErrNoResult)
+	})
+
+	t.Run("non-positive interval", func(t *testing.T) {
+		result := make(chan *APIResponse, 1)
+		result <- OK("", nil)
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, WriteWithHeartbeat(rec, result, 0))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestCreatedAt(t *testing.T) {
+	rec := httptest.NewRecorder()
+	assert.NoError(t, CreatedAt("/users/42", "", map[string]int{"id": 42}).Write(rec))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "/users/42", rec.Header().Get("Location"))
+}
+
+func TestAPIResponse_WithRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	assert.NoError(t, OK("", nil).WithRequestID("req-1").Write(rec))
+
+	assert.Equal(t, "req-1", rec.Header().Get("X-Request-ID"))
+	assert.Contains(t, rec.Body.String(), `"requestId":"req-1"`)
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	defer func(v int64) { MaxResponseBytes = v }(MaxResponseBytes)
+	MaxResponseBytes = 100
+
+	t.Run("within limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		assert.NoError(t, OK("", []int{1, 2, 3}).Write(rec))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("over limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		assert.NoError(t, OK("", strings.Repeat("x", 200)).Write(rec))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"errorCode":"RESPONSE_TOO_LARGE"`)
+	})
+}
+
+func TestAPIResponse_Write_unserializableData(t *testing.T) {
+	for name, data := range map[string]any{
+		"channel": map[string]any{"ch": make(chan int)},
+		"NaN":     math.NaN(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			assert.NoError(t, OK("", data).Write(rec))
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"errorCode":"SERIALIZATION_ERROR"`)
+		})
+	}
+}
+
+func TestAPIResponse_NoCompress(t *testing.T) {
+	assert.True(t, OK("", nil).Compressible())
+
+	t.Run("without a cache policy", func(t *testing.T) {
+		rsp := OK("", nil).NoCompress()
+		assert.False(t, rsp.Compressible())
+
+		rec := httptest.NewRecorder()
+		assert.NoError(t, rsp.Write(rec))
+		assert.Equal(t, "no-transform", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("with a cache policy", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		assert.NoError(t, OK("", nil).WithCache(time.Minute, true).NoCompress().Write(rec))
+		assert.Equal(t, "public, max-age=60, no-transform", rec.Header().Get("Cache-Control"))
+	})
+}